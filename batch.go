@@ -0,0 +1,173 @@
+package trace
+
+import (
+	"bufio"
+	"io"
+	"time"
+)
+
+// Longest time a batched or compressed line is held back before being written,
+// unless changed with SetFlushInterval
+const flushInterval = time.Second
+
+// batchWriter buffers the lines of a group output. It is only used on the log
+// goroutine so it needs no locking.
+type batchWriter struct {
+	w   io.Writer
+	buf *bufio.Writer
+}
+
+func (b *batchWriter) Write(p []byte) (n int, err error) {
+	return b.buf.Write(p)
+}
+
+func (b *batchWriter) flush(final bool) error {
+	if err := b.buf.Flush(); err != nil {
+		return err
+	}
+	if f, ok := b.w.(flusher); ok {
+		return f.flush(final)
+	}
+	return nil
+}
+
+func (b *batchWriter) Close() error {
+	err := b.buf.Flush()
+	closeOutput(b.w)
+	return err
+}
+
+// batched is a helper function for wrapping an output in a batchWriter. Outputs
+// that need the level of each line are not wrapped.
+func batched(output io.Writer) io.Writer {
+	switch output.(type) {
+	case *batchWriter, levelWriter:
+		return output
+	}
+	return &batchWriter{w: output, buf: bufio.NewWriter(output)}
+}
+
+// unbatched is a helper function for returning the output wrapped by a batchWriter
+func unbatched(output io.Writer) io.Writer {
+	if b, ok := output.(*batchWriter); ok {
+		return b.w
+	}
+	return output
+}
+
+type cmdSetBatch struct {
+	group int
+	on    bool
+}
+
+func (c *cmdSetBatch) do(l *Logger) {
+	g := l.getGroup(c.group)
+	if g == nil {
+		return
+	}
+
+	l.flushOutputs(false)
+	g.batched = c.on
+	for i, output := range g.outputs {
+		if c.on {
+			g.outputs[i] = batched(output)
+		} else {
+			g.outputs[i] = unbatched(output)
+		}
+	}
+}
+
+// flusher is implemented by outputs holding lines back, such as batched or
+// compressed outputs. If final is set the output is about to stop being
+// written and must be left complete.
+type flusher interface {
+	flush(final bool) error
+}
+
+// flushOutputs is a helper function for writing the lines held back by every
+// output of every group, including error and level outputs, and by the mirror.
+// Outputs shared by several groups are flushed once.
+func (l *Logger) flushOutputs(final bool) {
+	flushed := make(map[flusher]bool)
+	flush := func(group int, output io.Writer) {
+		if f, ok := output.(flusher); ok && !flushed[f] {
+			flushed[f] = true
+			if err := f.flush(final); err != nil {
+				l.errorHandler(group, err)
+			}
+		}
+	}
+
+	for id, g := range l.groups {
+		if g == nil {
+			continue
+		}
+		for _, output := range g.allOutputs() {
+			flush(id, output)
+		}
+	}
+	flush(DefaultGroupId, l.mirror)
+}
+
+// SetBatch turns on or off buffering the lines of the group's outputs so that
+// many lines are written with one call, which greatly reduces the number of
+// system calls at high log rates. Buffered lines are written at least once a
+// second, or as set with SetFlushInterval, on Flush, and on Done, so with
+// batching on they appear later and the last second of logs can be lost if the
+// program crashes. Error level messages written to SetErrorOutput and outputs
+// that need the level of each line, such as syslog, are not buffered. It is off
+// by default.
+func (l *Logger) SetBatch(group int, on bool) {
+	l.enqueue(&cmdSetBatch{group, on})
+}
+
+// SetBatch turns on or off buffering the lines of the group's outputs so that
+// many lines are written with one call, which greatly reduces the number of
+// system calls at high log rates. Buffered lines are written at least once a
+// second, or as set with SetFlushInterval, on Flush, and on Done, so with
+// batching on they appear later and the last second of logs can be lost if the
+// program crashes. Error level messages written to SetErrorOutput and outputs
+// that need the level of each line, such as syslog, are not buffered. It is off
+// by default.
+func SetBatch(group int, on bool) {
+	std.SetBatch(group, on)
+}
+
+type cmdSetFlushInterval struct {
+	d time.Duration
+}
+
+func (c *cmdSetFlushInterval) do(l *Logger) {
+	l.flushEvery = c.d
+	if l.flushTicker != nil {
+		l.flushTicker.Reset(l.flushPeriod())
+	}
+}
+
+// flushPeriod is a helper function for the interval set with SetFlushInterval
+func (l *Logger) flushPeriod() time.Duration {
+	if l.flushEvery <= 0 {
+		return flushInterval
+	}
+	return l.flushEvery
+}
+
+// SetFlushInterval sets the longest time lines of groups with SetBatch on, and
+// of compressed file groups, are held back before being written, which bounds
+// how late they appear on disk. Shorter intervals mean more system calls. The
+// summary lines of SetRateLimit and SetDedup are written at the same interval.
+// Lines are also written on Flush and Done, whatever the interval. A d of 0
+// restores the default of one second.
+func (l *Logger) SetFlushInterval(d time.Duration) {
+	l.enqueue(&cmdSetFlushInterval{d})
+}
+
+// SetFlushInterval sets the longest time lines of groups with SetBatch on, and
+// of compressed file groups, are held back before being written, which bounds
+// how late they appear on disk. Shorter intervals mean more system calls. The
+// summary lines of SetRateLimit and SetDedup are written at the same interval.
+// Lines are also written on Flush and Done, whatever the interval. A d of 0
+// restores the default of one second.
+func SetFlushInterval(d time.Duration) {
+	std.SetFlushInterval(d)
+}
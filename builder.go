@@ -0,0 +1,112 @@
+package trace
+
+import "time"
+
+// EntryBuilder builds up the fields of one log message, which is logged by Msg
+// or Msgf. Create one with NewEntry. It is not safe for concurrent use and must
+// not be used after Msg or Msgf.
+type EntryBuilder struct {
+	logger *Logger
+	group  int
+	level  Level
+	fields Fields
+}
+
+// NewEntry returns an EntryBuilder for a message to given group at info level,
+// for events with many fields:
+//
+//	trace.NewEntry(g).Str("user", u).Int("code", c).Msg("login")
+//
+// The fields are written like those of WithFields, in the active format.
+func (l *Logger) NewEntry(group int) *EntryBuilder {
+	return &EntryBuilder{logger: l, group: group, level: LevelInfo, fields: make(Fields)}
+}
+
+// NewEntry returns an EntryBuilder for a message to given group at info level,
+// for events with many fields:
+//
+//	trace.NewEntry(g).Str("user", u).Int("code", c).Msg("login")
+//
+// The fields are written like those of WithFields, in the active format.
+func NewEntry(group int) *EntryBuilder {
+	return std.NewEntry(group)
+}
+
+// Level sets the level the message is logged at
+func (e *EntryBuilder) Level(lvl Level) *EntryBuilder {
+	e.level = lvl
+	return e
+}
+
+// Any adds a field of any type
+func (e *EntryBuilder) Any(key string, value interface{}) *EntryBuilder {
+	e.fields[key] = value
+	return e
+}
+
+// Bool adds a bool field
+func (e *EntryBuilder) Bool(key string, value bool) *EntryBuilder {
+	e.fields[key] = value
+	return e
+}
+
+// Dur adds a time.Duration field
+func (e *EntryBuilder) Dur(key string, value time.Duration) *EntryBuilder {
+	e.fields[key] = value
+	return e
+}
+
+// Err adds the text of the error as a field named "error". A nil error is not
+// added.
+func (e *EntryBuilder) Err(err error) *EntryBuilder {
+	if err != nil {
+		e.fields["error"] = err.Error()
+	}
+	return e
+}
+
+// Float64 adds a float64 field
+func (e *EntryBuilder) Float64(key string, value float64) *EntryBuilder {
+	e.fields[key] = value
+	return e
+}
+
+// Int adds an int field
+func (e *EntryBuilder) Int(key string, value int) *EntryBuilder {
+	e.fields[key] = value
+	return e
+}
+
+// Int64 adds an int64 field
+func (e *EntryBuilder) Int64(key string, value int64) *EntryBuilder {
+	e.fields[key] = value
+	return e
+}
+
+// Str adds a string field
+func (e *EntryBuilder) Str(key, value string) *EntryBuilder {
+	e.fields[key] = value
+	return e
+}
+
+// Time adds a time.Time field
+func (e *EntryBuilder) Time(key string, value time.Time) *EntryBuilder {
+	e.fields[key] = value
+	return e
+}
+
+// Uint64 adds a uint64 field
+func (e *EntryBuilder) Uint64(key string, value uint64) *EntryBuilder {
+	e.fields[key] = value
+	return e
+}
+
+// Msg logs the message with the fields added so far
+func (e *EntryBuilder) Msg(msg string) {
+	e.logger.logFields(e.group, e.level, e.fields, "", msg)
+}
+
+// Msgf logs the message with the fields added so far. Similar to fmt.Printf(...)
+func (e *EntryBuilder) Msgf(format string, a ...interface{}) {
+	e.logger.logFields(e.group, e.level, e.fields, format, a...)
+}
@@ -0,0 +1,88 @@
+package trace
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// CallerMode selects how the caller of each log call is shown, set with SetCallerMode
+type CallerMode int32
+
+const (
+	// CallerNone does not capture the caller. It is the default.
+	CallerNone CallerMode = iota
+
+	// CallerFile shows the source file and line, such as "server.go:42"
+	CallerFile
+
+	// CallerFunc shows the function, with its package path trimmed to the last
+	// element, such as "server.(*Handler).ServeHTTP"
+	CallerFunc
+
+	// CallerFullFunc shows the function with its full package path, such as
+	// "example.com/app/server.(*Handler).ServeHTTP"
+	CallerFullFunc
+)
+
+// describe is a helper function for formatting the caller at pc, file, and line for the mode
+func (mode CallerMode) describe(pc uintptr, file string, line int) string {
+	switch mode {
+	case CallerFunc, CallerFullFunc:
+		f := runtime.FuncForPC(pc)
+		if f == nil {
+			return filepath.Base(file) + ":" + strconv.Itoa(line)
+		}
+		name := f.Name()
+		if mode == CallerFunc {
+			name = name[strings.LastIndexByte(name, '/')+1:]
+		}
+		return name
+	default:
+		return filepath.Base(file) + ":" + strconv.Itoa(line)
+	}
+}
+
+// SetCallerMode sets how the caller of every log call is captured and shown:
+// CallerNone, CallerFile for the source file and line, CallerFunc for the
+// function with a short package name, or CallerFullFunc for the function with
+// its full package path. The caller is captured on the calling goroutine, which
+// has a measurable cost, so it is CallerNone by default. The change applies to
+// log calls made after SetCallerMode returns.
+func (l *Logger) SetCallerMode(mode CallerMode) {
+	l.callerMode.Store(int32(mode))
+}
+
+// SetCallerMode sets how the caller of every log call is captured and shown:
+// CallerNone, CallerFile for the source file and line, CallerFunc for the
+// function with a short package name, or CallerFullFunc for the function with
+// its full package path. The caller is captured on the calling goroutine, which
+// has a measurable cost, so it is CallerNone by default. The change applies to
+// log calls made after SetCallerMode returns.
+func SetCallerMode(mode CallerMode) {
+	std.SetCallerMode(mode)
+}
+
+// SetCallerSkip sets how many extra stack frames are skipped when capturing the
+// caller, so that packages wrapping the logging functions report their own
+// caller instead of themselves. The default of 0 is right when the logging
+// functions are called directly; a wrapper function that calls Info adds 1, and
+// one more for each further level of wrapping. Negative values are treated as
+// 0. The change applies to log calls made after SetCallerSkip returns.
+func (l *Logger) SetCallerSkip(n int) {
+	if n < 0 {
+		n = 0
+	}
+	l.extraSkip.Store(int32(n))
+}
+
+// SetCallerSkip sets how many extra stack frames are skipped when capturing the
+// caller, so that packages wrapping the logging functions report their own
+// caller instead of themselves. The default of 0 is right when the logging
+// functions are called directly; a wrapper function that calls Info adds 1, and
+// one more for each further level of wrapping. Negative values are treated as
+// 0. The change applies to log calls made after SetCallerSkip returns.
+func SetCallerSkip(n int) {
+	std.SetCallerSkip(n)
+}
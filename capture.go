@@ -0,0 +1,89 @@
+package trace
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// captureBuffer is an io.Writer collecting the lines of Capture
+type captureBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (c *captureBuffer) Write(p []byte) (n int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.Write(p)
+}
+
+// lines is a helper function for splitting the captured output into lines without newlines
+func (c *captureBuffer) lines() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	text := strings.TrimSuffix(c.buf.String(), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+type cmdSwapDefaultOutputs struct {
+	outputs      []io.Writer
+	errOutput    io.Writer
+	levelOutputs [LevelError + 1]io.Writer
+	done         chan struct{}
+}
+
+// do replaces the default group's outputs and returns the previous ones in c
+func (c *cmdSwapDefaultOutputs) do(l *Logger) {
+	g := l.groups[DefaultGroupId]
+	g.outputs, c.outputs = c.outputs, g.outputs
+	g.errOutput, c.errOutput = c.errOutput, g.errOutput
+	g.levelOutputs, c.levelOutputs = c.levelOutputs, g.levelOutputs
+	close(c.done)
+}
+
+// swapDefaultOutputs is a helper function for replacing every output of the
+// default group, returning the previous ones
+func (l *Logger) swapDefaultOutputs(c *cmdSwapDefaultOutputs) *cmdSwapDefaultOutputs {
+	c.done = make(chan struct{})
+	l.enqueue(c)
+	<-c.done
+	return c
+}
+
+// Capture runs f with the default group writing to memory instead of its
+// outputs, and returns the lines f logged to it, without their newlines, for
+// asserting on log output in tests:
+//
+//	lines := trace.Capture(func() { trace.Info("started") })
+//
+// Messages of every level are captured, including those that would go to
+// outputs set with SetErrorOutput or SetLevelOutput, or to os.Stderr with
+// SplitStdStreams. The outputs are restored when f returns, even if it panics.
+func (l *Logger) Capture(f func()) []string {
+	buf := &captureBuffer{}
+	previous := l.swapDefaultOutputs(&cmdSwapDefaultOutputs{outputs: []io.Writer{buf}})
+	defer l.swapDefaultOutputs(previous)
+
+	f()
+	l.Flush()
+	return buf.lines()
+}
+
+// Capture runs f with the default group writing to memory instead of its
+// outputs, and returns the lines f logged to it, without their newlines, for
+// asserting on log output in tests:
+//
+//	lines := trace.Capture(func() { trace.Info("started") })
+//
+// Messages of every level are captured, including those that would go to
+// outputs set with SetErrorOutput or SetLevelOutput, or to os.Stderr with
+// SplitStdStreams. The outputs are restored when f returns, even if it panics.
+func Capture(f func()) []string {
+	return std.Capture(f)
+}
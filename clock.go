@@ -0,0 +1,34 @@
+package trace
+
+import "time"
+
+// Clock function, wrapped so atomic.Value always stores the same type
+type clockFunc struct {
+	now func() time.Time
+}
+
+// SetClock sets the function giving the time of log messages, so that tests
+// can use a fixed clock and compare timestamps exactly. It is also used for
+// rate limits, summary lines and timers. A nil function restores time.Now,
+// which is the default. The change applies to log calls made after SetClock
+// returns.
+func (l *Logger) SetClock(now func() time.Time) {
+	l.clock.Store(clockFunc{now})
+}
+
+// SetClock sets the function giving the time of log messages, so that tests
+// can use a fixed clock and compare timestamps exactly. It is also used for
+// rate limits, summary lines and timers. A nil function restores time.Now,
+// which is the default. The change applies to log calls made after SetClock
+// returns.
+func SetClock(now func() time.Time) {
+	std.SetClock(now)
+}
+
+// now is a helper function for reading the clock set with SetClock
+func (l *Logger) now() time.Time {
+	if c, ok := l.clock.Load().(clockFunc); ok && c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,77 @@
+package trace
+
+import (
+	"io"
+	"os"
+)
+
+// ColorMode selects when level names are colored with ANSI escape codes
+type ColorMode int
+
+const (
+	// ColorNever writes plain text. This is the default
+	ColorNever ColorMode = iota
+
+	// ColorAuto colors the output only when it is a terminal
+	ColorAuto
+
+	// ColorAlways colors every output
+	ColorAlways
+)
+
+// ANSI escape codes for each level, indexed by Level
+var levelColors = [...]string{"", "\x1b[90m", "\x1b[32m", "\x1b[33m", "\x1b[31m"}
+
+const colorReset = "\x1b[0m"
+
+type cmdSetColor struct {
+	mode ColorMode
+}
+
+func (c *cmdSetColor) do(l *Logger) {
+	l.colorMode = c.mode
+}
+
+// SetColor sets when the level name of FormatText lines is colored: gray for
+// trace, green for info, yellow for warn, and red for error. ColorAuto colors
+// only outputs that are an *os.File connected to a terminal, so files and other
+// writers never receive escape codes. FormatJSON is never colored.
+func (l *Logger) SetColor(mode ColorMode) {
+	l.enqueue(&cmdSetColor{mode})
+}
+
+// SetColor sets when the level name of FormatText lines is colored: gray for
+// trace, green for info, yellow for warn, and red for error. ColorAuto colors
+// only outputs that are an *os.File connected to a terminal, so files and other
+// writers never receive escape codes. FormatJSON is never colored.
+func SetColor(mode ColorMode) {
+	std.SetColor(mode)
+}
+
+// useColor is a helper function reporting whether lines written to output are
+// colored. A batched output is colored if the output it wraps is a terminal.
+func (l *Logger) useColor(output io.Writer) bool {
+	if l.formatter != nil || l.outputFormat != FormatText {
+		return false
+	}
+
+	switch l.colorMode {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		return isTerminal(unbatched(output))
+	default:
+		return false
+	}
+}
+
+// isTerminal is a helper function reporting whether output is a character device such as a terminal
+func isTerminal(output io.Writer) bool {
+	f, ok := output.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,152 @@
+package trace
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// configState is the JSON body of ConfigHandler responses
+type configState struct {
+	Trace    bool          `json:"trace"`
+	MinLevel string        `json:"minLevel"`
+	Groups   []configGroup `json:"groups"`
+}
+
+type configGroup struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// configChange is the JSON body of ConfigHandler POST requests. Fields left
+// out are not changed.
+type configChange struct {
+	Trace    *bool           `json:"trace"`
+	MinLevel *string         `json:"minLevel"`
+	Groups   map[string]bool `json:"groups"`
+}
+
+// parseLevel is a helper function for looking up a level by its name. A level
+// added with RegisterLevel gives its severity, the level its messages are
+// filtered as, raised to LevelTrace if below it.
+func parseLevel(name string) (Level, bool) {
+	if name == "" {
+		return 0, false
+	}
+	for lvl, levelName := range levelNames {
+		if levelName == name {
+			return Level(lvl), true
+		}
+	}
+
+	customLevelsMu.RLock()
+	defer customLevelsMu.RUnlock()
+
+	for _, c := range customLevels {
+		if c.name != name {
+			continue
+		}
+		if c.severity < LevelTrace {
+			return LevelTrace, true
+		}
+		return c.severity, true
+	}
+	return 0, false
+}
+
+// configState is a helper function for reading the current configuration
+func (l *Logger) configState() configState {
+	state := configState{
+		Trace:    l.IsTraceEnabled(),
+		MinLevel: Level(l.minLevel.Load()).String(),
+	}
+	for _, info := range l.ListGroups() {
+		state.Groups = append(state.Groups, configGroup{info.ID, info.Name, info.Enabled})
+	}
+	return state
+}
+
+// ConfigHandler returns an http.Handler for changing the logging configuration
+// at runtime from an admin endpoint. GET responds with the trace state, the
+// minimum level and the registered groups as JSON:
+//
+//	{"trace":false,"minLevel":"INFO","groups":[{"id":0,"name":"","enabled":true}]}
+//
+// POST takes a JSON body with any of "trace", "minLevel" and "groups", a map
+// of group names to whether they are enabled, applies it and responds like GET.
+// Nothing is changed if the body names an unknown level or group. Changes are
+// queued like EnableTrace, SetMinLevel and EnableGroup calls. The handler does
+// no authentication, so it should only be served to operators.
+func (l *Logger) ConfigHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var change configChange
+			if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := l.applyConfig(change); err != "" {
+				http.Error(w, err, http.StatusBadRequest)
+				return
+			}
+			l.Flush()
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(l.configState())
+	})
+}
+
+// applyConfig is a helper function for queueing the changes of a ConfigHandler
+// POST. It returns a description of the first invalid value without changing
+// anything, or "" once the changes are queued.
+func (l *Logger) applyConfig(change configChange) string {
+	var min Level
+	if change.MinLevel != nil {
+		var ok bool
+		if min, ok = parseLevel(*change.MinLevel); !ok {
+			return "unknown level " + *change.MinLevel
+		}
+	}
+
+	groups := make(map[int]bool, len(change.Groups))
+	for name, on := range change.Groups {
+		id, ok := l.GroupByName(name)
+		if !ok {
+			return "unknown group " + name
+		}
+		groups[id] = on
+	}
+
+	if change.MinLevel != nil {
+		l.SetMinLevel(min)
+	}
+	if change.Trace != nil {
+		l.EnableTrace(*change.Trace)
+	}
+	for id, on := range groups {
+		l.EnableGroup(id, on)
+	}
+	return ""
+}
+
+// ConfigHandler returns an http.Handler for changing the logging configuration
+// at runtime from an admin endpoint. GET responds with the trace state, the
+// minimum level and the registered groups as JSON:
+//
+//	{"trace":false,"minLevel":"INFO","groups":[{"id":0,"name":"","enabled":true}]}
+//
+// POST takes a JSON body with any of "trace", "minLevel" and "groups", a map
+// of group names to whether they are enabled, applies it and responds like GET.
+// Nothing is changed if the body names an unknown level or group. Changes are
+// queued like EnableTrace, SetMinLevel and EnableGroup calls. The handler does
+// no authentication, so it should only be served to operators.
+func ConfigHandler() http.Handler {
+	return std.ConfigHandler()
+}
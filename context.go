@@ -0,0 +1,205 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+)
+
+// Context key holding the request ID, wrapped so atomic.Value always stores the same type
+type contextKey struct {
+	key interface{}
+}
+
+// SetContextIDKey sets the context key whose value the Ctx functions include in
+// the output as the request ID, for example "[id=abc123]". The value is formatted
+// with fmt.Sprint. A nil key stops including IDs.
+func (l *Logger) SetContextIDKey(key interface{}) {
+	l.contextIDKey.Store(contextKey{key})
+}
+
+// SetContextIDKey sets the context key whose value the Ctx functions include in
+// the output as the request ID, for example "[id=abc123]". The value is formatted
+// with fmt.Sprint. A nil key stops including IDs.
+func SetContextIDKey(key interface{}) {
+	std.SetContextIDKey(key)
+}
+
+// logCtx is a helper function for processing log requests bound to a context.
+// Nothing is logged once the context is cancelled.
+func (l *Logger) logCtx(ctx context.Context, group int, lvl Level, format string, a ...interface{}) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	l.send(lvl, msgData{group: group, id: l.contextID(ctx)}, format, a...)
+}
+
+// contextID is a helper function for extracting the ID set with SetContextIDKey from a context
+func (l *Logger) contextID(ctx context.Context) string {
+	if k, ok := l.contextIDKey.Load().(contextKey); ok && k.key != nil {
+		if id := ctx.Value(k.key); id != nil {
+			return fmt.Sprint(id)
+		}
+	}
+	return ""
+}
+
+// ErrorCtx logs a message to default group at error level unless ctx is cancelled. Similar to fmt.Print(...)
+func (l *Logger) ErrorCtx(ctx context.Context, a ...interface{}) {
+	l.logCtx(ctx, 0, LevelError, "", a...)
+}
+
+// ErrorfCtx logs a message to default group at error level unless ctx is cancelled. Similar to fmt.Printf(...)
+func (l *Logger) ErrorfCtx(ctx context.Context, format string, a ...interface{}) {
+	l.logCtx(ctx, 0, LevelError, format, a...)
+}
+
+// ErrorgCtx logs a message to given group at error level unless ctx is cancelled. Similar to fmt.Print(...)
+func (l *Logger) ErrorgCtx(ctx context.Context, group int, a ...interface{}) {
+	l.logCtx(ctx, group, LevelError, "", a...)
+}
+
+// ErrorgfCtx logs a message to given group at error level unless ctx is cancelled. Similar to fmt.Printf(...)
+func (l *Logger) ErrorgfCtx(ctx context.Context, group int, format string, a ...interface{}) {
+	l.logCtx(ctx, group, LevelError, format, a...)
+}
+
+// InfoCtx logs a message to default group at info level unless ctx is cancelled. Similar to fmt.Print(...)
+func (l *Logger) InfoCtx(ctx context.Context, a ...interface{}) {
+	l.logCtx(ctx, 0, LevelInfo, "", a...)
+}
+
+// InfofCtx logs a message to default group at info level unless ctx is cancelled. Similar to fmt.Printf(...)
+func (l *Logger) InfofCtx(ctx context.Context, format string, a ...interface{}) {
+	l.logCtx(ctx, 0, LevelInfo, format, a...)
+}
+
+// InfogCtx logs a message to given group at info level unless ctx is cancelled. Similar to fmt.Print(...)
+func (l *Logger) InfogCtx(ctx context.Context, group int, a ...interface{}) {
+	l.logCtx(ctx, group, LevelInfo, "", a...)
+}
+
+// InfogfCtx logs a message to given group at info level unless ctx is cancelled. Similar to fmt.Printf(...)
+func (l *Logger) InfogfCtx(ctx context.Context, group int, format string, a ...interface{}) {
+	l.logCtx(ctx, group, LevelInfo, format, a...)
+}
+
+// TraceCtx logs a message to default group at trace level unless ctx is cancelled. Similar to fmt.Print(...)
+func (l *Logger) TraceCtx(ctx context.Context, a ...interface{}) {
+	l.logCtx(ctx, 0, LevelTrace, "", a...)
+}
+
+// TracefCtx logs a message to default group at trace level unless ctx is cancelled. Similar to fmt.Printf(...)
+func (l *Logger) TracefCtx(ctx context.Context, format string, a ...interface{}) {
+	l.logCtx(ctx, 0, LevelTrace, format, a...)
+}
+
+// TracegCtx logs a message to given group at trace level unless ctx is cancelled. Similar to fmt.Print(...)
+func (l *Logger) TracegCtx(ctx context.Context, group int, a ...interface{}) {
+	l.logCtx(ctx, group, LevelTrace, "", a...)
+}
+
+// TracegfCtx logs a message to given group at trace level unless ctx is cancelled. Similar to fmt.Printf(...)
+func (l *Logger) TracegfCtx(ctx context.Context, group int, format string, a ...interface{}) {
+	l.logCtx(ctx, group, LevelTrace, format, a...)
+}
+
+// WarnCtx logs a message to default group at warn level unless ctx is cancelled. Similar to fmt.Print(...)
+func (l *Logger) WarnCtx(ctx context.Context, a ...interface{}) {
+	l.logCtx(ctx, 0, LevelWarn, "", a...)
+}
+
+// WarnfCtx logs a message to default group at warn level unless ctx is cancelled. Similar to fmt.Printf(...)
+func (l *Logger) WarnfCtx(ctx context.Context, format string, a ...interface{}) {
+	l.logCtx(ctx, 0, LevelWarn, format, a...)
+}
+
+// WarngCtx logs a message to given group at warn level unless ctx is cancelled. Similar to fmt.Print(...)
+func (l *Logger) WarngCtx(ctx context.Context, group int, a ...interface{}) {
+	l.logCtx(ctx, group, LevelWarn, "", a...)
+}
+
+// WarngfCtx logs a message to given group at warn level unless ctx is cancelled. Similar to fmt.Printf(...)
+func (l *Logger) WarngfCtx(ctx context.Context, group int, format string, a ...interface{}) {
+	l.logCtx(ctx, group, LevelWarn, format, a...)
+}
+
+// ErrorCtx logs a message to default group at error level unless ctx is cancelled. Similar to fmt.Print(...)
+func ErrorCtx(ctx context.Context, a ...interface{}) {
+	std.logCtx(ctx, 0, LevelError, "", a...)
+}
+
+// ErrorfCtx logs a message to default group at error level unless ctx is cancelled. Similar to fmt.Printf(...)
+func ErrorfCtx(ctx context.Context, format string, a ...interface{}) {
+	std.logCtx(ctx, 0, LevelError, format, a...)
+}
+
+// ErrorgCtx logs a message to given group at error level unless ctx is cancelled. Similar to fmt.Print(...)
+func ErrorgCtx(ctx context.Context, group int, a ...interface{}) {
+	std.logCtx(ctx, group, LevelError, "", a...)
+}
+
+// ErrorgfCtx logs a message to given group at error level unless ctx is cancelled. Similar to fmt.Printf(...)
+func ErrorgfCtx(ctx context.Context, group int, format string, a ...interface{}) {
+	std.logCtx(ctx, group, LevelError, format, a...)
+}
+
+// InfoCtx logs a message to default group at info level unless ctx is cancelled. Similar to fmt.Print(...)
+func InfoCtx(ctx context.Context, a ...interface{}) {
+	std.logCtx(ctx, 0, LevelInfo, "", a...)
+}
+
+// InfofCtx logs a message to default group at info level unless ctx is cancelled. Similar to fmt.Printf(...)
+func InfofCtx(ctx context.Context, format string, a ...interface{}) {
+	std.logCtx(ctx, 0, LevelInfo, format, a...)
+}
+
+// InfogCtx logs a message to given group at info level unless ctx is cancelled. Similar to fmt.Print(...)
+func InfogCtx(ctx context.Context, group int, a ...interface{}) {
+	std.logCtx(ctx, group, LevelInfo, "", a...)
+}
+
+// InfogfCtx logs a message to given group at info level unless ctx is cancelled. Similar to fmt.Printf(...)
+func InfogfCtx(ctx context.Context, group int, format string, a ...interface{}) {
+	std.logCtx(ctx, group, LevelInfo, format, a...)
+}
+
+// TraceCtx logs a message to default group at trace level unless ctx is cancelled. Similar to fmt.Print(...)
+func TraceCtx(ctx context.Context, a ...interface{}) {
+	std.logCtx(ctx, 0, LevelTrace, "", a...)
+}
+
+// TracefCtx logs a message to default group at trace level unless ctx is cancelled. Similar to fmt.Printf(...)
+func TracefCtx(ctx context.Context, format string, a ...interface{}) {
+	std.logCtx(ctx, 0, LevelTrace, format, a...)
+}
+
+// TracegCtx logs a message to given group at trace level unless ctx is cancelled. Similar to fmt.Print(...)
+func TracegCtx(ctx context.Context, group int, a ...interface{}) {
+	std.logCtx(ctx, group, LevelTrace, "", a...)
+}
+
+// TracegfCtx logs a message to given group at trace level unless ctx is cancelled. Similar to fmt.Printf(...)
+func TracegfCtx(ctx context.Context, group int, format string, a ...interface{}) {
+	std.logCtx(ctx, group, LevelTrace, format, a...)
+}
+
+// WarnCtx logs a message to default group at warn level unless ctx is cancelled. Similar to fmt.Print(...)
+func WarnCtx(ctx context.Context, a ...interface{}) {
+	std.logCtx(ctx, 0, LevelWarn, "", a...)
+}
+
+// WarnfCtx logs a message to default group at warn level unless ctx is cancelled. Similar to fmt.Printf(...)
+func WarnfCtx(ctx context.Context, format string, a ...interface{}) {
+	std.logCtx(ctx, 0, LevelWarn, format, a...)
+}
+
+// WarngCtx logs a message to given group at warn level unless ctx is cancelled. Similar to fmt.Print(...)
+func WarngCtx(ctx context.Context, group int, a ...interface{}) {
+	std.logCtx(ctx, group, LevelWarn, "", a...)
+}
+
+// WarngfCtx logs a message to given group at warn level unless ctx is cancelled. Similar to fmt.Printf(...)
+func WarngfCtx(ctx context.Context, group int, format string, a ...interface{}) {
+	std.logCtx(ctx, group, LevelWarn, format, a...)
+}
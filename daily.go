@@ -0,0 +1,116 @@
+package trace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Layout of the date inserted into the names of daily files
+const dailyLayout = "2006-01-02"
+
+// dailyFile is an io.Writer to a file named after the current UTC day, which
+// is closed and replaced by a new file when the day changes. It is only used on
+// the log goroutine, so it needs no locking.
+type dailyFile struct {
+	pattern string
+	now     func() time.Time
+	day     string
+	file    *os.File
+}
+
+// dailyPath is a helper function for inserting the day before the extension of pattern
+func dailyPath(pattern, day string) string {
+	ext := filepath.Ext(pattern)
+	return strings.TrimSuffix(pattern, ext) + "-" + day + ext
+}
+
+// open closes the current file, if any, and opens or appends to the file of day
+func (d *dailyFile) open(day string) error {
+	if err := d.close(); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(dailyPath(d.pattern, day), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	d.file = file
+	d.day = day
+	return nil
+}
+
+func (d *dailyFile) close() error {
+	if d.file == nil {
+		return nil
+	}
+	err := d.file.Close()
+	d.file = nil
+	return err
+}
+
+func (d *dailyFile) Write(p []byte) (n int, err error) {
+	if day := d.now().UTC().Format(dailyLayout); d.file == nil || day != d.day {
+		if err := d.open(day); err != nil {
+			return 0, err
+		}
+	}
+	return d.file.Write(p)
+}
+
+// flush closes the file if final is set. The next write opens it again, or
+// the file of the next day.
+func (d *dailyFile) flush(final bool) error {
+	if !final {
+		return nil
+	}
+	return d.close()
+}
+
+// Sync commits the current file to stable storage
+func (d *dailyFile) Sync() error {
+	if d.file == nil {
+		return nil
+	}
+	return d.file.Sync()
+}
+
+func (d *dailyFile) Close() error {
+	return d.close()
+}
+
+// RegisterDailyFileGroup registers a new logging group that appends to one file
+// per UTC day. The date is inserted before the extension of dirPattern, so
+// "/var/log/audit.log" gives files such as "/var/log/audit-2024-06-01.log". The
+// date is checked, using the clock set with SetClock, on every write, and at
+// midnight UTC the previous day's file is closed and the next one opened. The
+// file is also closed on Done and Close. It returns ErrGroupExists, and the
+// existing group's ID, if the group name already exists.
+func (l *Logger) RegisterDailyFileGroup(name, dirPattern string, on bool) (int, error) {
+	if id, ok := l.GroupByName(name); ok {
+		return id, ErrGroupExists
+	}
+
+	d := &dailyFile{pattern: dirPattern, now: l.now}
+	if err := d.open(l.now().UTC().Format(dailyLayout)); err != nil {
+		return 0, err
+	}
+
+	group, err := l.RegisterGroupE(name, d, on)
+	if err != nil {
+		d.Close()
+	}
+	return group, err
+}
+
+// RegisterDailyFileGroup registers a new logging group that appends to one file
+// per UTC day. The date is inserted before the extension of dirPattern, so
+// "/var/log/audit.log" gives files such as "/var/log/audit-2024-06-01.log". The
+// date is checked, using the clock set with SetClock, on every write, and at
+// midnight UTC the previous day's file is closed and the next one opened. The
+// file is also closed on Done and Close. It returns ErrGroupExists, and the
+// existing group's ID, if the group name already exists.
+func RegisterDailyFileGroup(name, dirPattern string, on bool) (int, error) {
+	return std.RegisterDailyFileGroup(name, dirPattern, on)
+}
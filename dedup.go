@@ -0,0 +1,86 @@
+package trace
+
+import (
+	"fmt"
+	"time"
+)
+
+// dedupState tracks the last message of a group to collapse repeats of it.
+// It is only used on the log goroutine so it needs no locking.
+type dedupState struct {
+	key     string
+	lvl     Level
+	repeats int
+}
+
+// dedupKey is a helper function for the text messages are compared by: everything but the time
+func dedupKey(lvl Level, m *msgData) string {
+	return lvl.String() + " [id=" + m.id + "] " + m.caller + " " + m.msg + textFields(m.fields, " ")
+}
+
+// repeated is a helper function reporting whether m repeats the last message, counting it if so
+func (d *dedupState) repeated(lvl Level, m *msgData) bool {
+	if d.key != "" && d.key == dedupKey(lvl, m) {
+		d.repeats++
+		return true
+	}
+	return false
+}
+
+// last is a helper function for remembering m as the message later ones are compared to
+func (d *dedupState) last(lvl Level, m *msgData) {
+	d.key = dedupKey(lvl, m)
+	d.lvl = lvl
+}
+
+// writeRepeated is a helper function for writing the summary line of repeats
+// the group's deduplication collapsed since the last summary.
+func (l *Logger) writeRepeated(group int, t time.Time) {
+	g := l.groups[group]
+	if g.dedup == nil || g.dedup.repeats == 0 {
+		return
+	}
+
+	summary := msgData{group: group, t: t, msg: fmt.Sprintf("last message repeated %d times", g.dedup.repeats)}
+	g.dedup.repeats = 0
+	l.writeMsg(g.dedup.lvl, &summary)
+}
+
+type cmdSetDedup struct {
+	group int
+	on    bool
+}
+
+func (c *cmdSetDedup) do(l *Logger) {
+	g := l.getGroup(c.group)
+	if g == nil {
+		return
+	}
+
+	l.writeRepeated(c.group, l.now())
+	if c.on {
+		g.dedup = &dedupState{}
+	} else {
+		g.dedup = nil
+	}
+}
+
+// SetDedup turns on or off collapsing consecutive identical messages of the
+// group. Messages are identical when everything but the timestamp matches. The
+// repeats are counted instead of output, and a line such as "last message
+// repeated 12 times" is written when a different message arrives, at least
+// once a second, or as set with SetFlushInterval, on Flush, and on Done. It is
+// off by default.
+func (l *Logger) SetDedup(group int, on bool) {
+	l.enqueue(&cmdSetDedup{group, on})
+}
+
+// SetDedup turns on or off collapsing consecutive identical messages of the
+// group. Messages are identical when everything but the timestamp matches. The
+// repeats are counted instead of output, and a line such as "last message
+// repeated 12 times" is written when a different message arrives, at least
+// once a second, or as set with SetFlushInterval, on Flush, and on Done. It is
+// off by default.
+func SetDedup(group int, on bool) {
+	std.SetDedup(group, on)
+}
@@ -0,0 +1,30 @@
+package trace
+
+import "io"
+
+// discards is a helper function reporting whether every output is io.Discard,
+// so that formatting lines for them can be skipped
+func discards(outputs []io.Writer) bool {
+	for _, output := range outputs {
+		if unbatched(output) != io.Discard {
+			return false
+		}
+	}
+	return true
+}
+
+// RegisterDiscardGroup registers a new logging group writing to io.Discard. Its
+// messages go through the whole logging pipeline except formatting, which is
+// skipped, so it measures the overhead of logging calls in benchmarks. Like
+// RegisterGroup it panics if the group name already exists.
+func (l *Logger) RegisterDiscardGroup(name string, on bool) int {
+	return l.RegisterGroup(name, io.Discard, on)
+}
+
+// RegisterDiscardGroup registers a new logging group writing to io.Discard. Its
+// messages go through the whole logging pipeline except formatting, which is
+// skipped, so it measures the overhead of logging calls in benchmarks. Like
+// RegisterGroup it panics if the group name already exists.
+func RegisterDiscardGroup(name string, on bool) int {
+	return std.RegisterDiscardGroup(name, on)
+}
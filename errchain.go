@@ -0,0 +1,132 @@
+package trace
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// chainLink is one error of an error chain as written by FormatJSON
+type chainLink struct {
+	Type string `json:"type"`
+	Msg  string `json:"msg"`
+}
+
+// errorChain is a helper function for listing err and the errors it wraps, as found by errors.Unwrap
+func errorChain(err error) []chainLink {
+	var chain []chainLink
+	for ; err != nil; err = errors.Unwrap(err) {
+		chain = append(chain, chainLink{Type: fmt.Sprintf("%T", err), Msg: err.Error()})
+	}
+	return chain
+}
+
+// textChain is a helper function for rendering an error chain as "type: msg <- type: msg"
+func textChain(chain []chainLink) string {
+	links := make([]string, len(chain))
+	for i, link := range chain {
+		links[i] = link.Type + ": " + link.Msg
+	}
+	return strings.Join(links, " <- ")
+}
+
+// logErr is a helper function for logging an error at error level
+func (l *Logger) logErr(group int, err error) {
+	msg := "nil error"
+	if err != nil {
+		msg = err.Error()
+	}
+	l.send(LevelError, msgData{group: group, err: err}, "", msg)
+}
+
+type cmdSetErrorChain struct {
+	on bool
+}
+
+func (c *cmdSetErrorChain) do(l *Logger) {
+	l.errorChain = c.on
+}
+
+// SetErrorChain turns on or off writing the chain of errors wrapped by errors
+// logged with ErrorErr and ErrorgErr, found with errors.Unwrap, together with
+// their types. FormatText appends it to the message like
+// "(chain: *fmt.wrapError: open failed: denied <- *errors.errorString: denied)",
+// FormatJSON writes it as a "chain" array of objects with "type" and "msg"
+// keys, and FormatLogfmt as a "chain" key. It is off by default.
+func (l *Logger) SetErrorChain(on bool) {
+	l.enqueue(&cmdSetErrorChain{on})
+}
+
+// SetErrorChain turns on or off writing the chain of errors wrapped by errors
+// logged with ErrorErr and ErrorgErr, found with errors.Unwrap, together with
+// their types. FormatText appends it to the message like
+// "(chain: *fmt.wrapError: open failed: denied <- *errors.errorString: denied)",
+// FormatJSON writes it as a "chain" array of objects with "type" and "msg"
+// keys, and FormatLogfmt as a "chain" key. It is off by default.
+func SetErrorChain(on bool) {
+	std.SetErrorChain(on)
+}
+
+// ErrorErr logs an error to default group at error level. A nil error is logged as "nil error"
+func (l *Logger) ErrorErr(err error) {
+	l.logErr(DefaultGroupId, err)
+}
+
+// ErrorgErr logs an error to given group at error level. A nil error is logged as "nil error"
+func (l *Logger) ErrorgErr(group int, err error) {
+	l.logErr(group, err)
+}
+
+// ErrorErr logs an error to default group at error level. A nil error is logged as "nil error"
+func ErrorErr(err error) {
+	std.logErr(DefaultGroupId, err)
+}
+
+// ErrorgErr logs an error to given group at error level. A nil error is logged as "nil error"
+func ErrorgErr(group int, err error) {
+	std.logErr(group, err)
+}
+
+// Errorsf logs a message to default group at error level and returns it, so
+// that it can also be returned up the stack without formatting it twice:
+//
+//	return errors.New(trace.Errorsf("open %s: %v", path, err))
+//
+// Similar to fmt.Sprintf(...)
+func (l *Logger) Errorsf(format string, a ...interface{}) string {
+	msg := fmt.Sprintf(format, a...)
+	l.log(DefaultGroupId, LevelError, "", msg)
+	return msg
+}
+
+// ErrorfE logs a message to default group at error level like ErrorErr and
+// returns it as an error. Similar to fmt.Errorf(...), so %w wraps an error:
+//
+//	return trace.ErrorfE("open %s: %w", path, err)
+func (l *Logger) ErrorfE(format string, a ...interface{}) error {
+	err := fmt.Errorf(format, a...)
+	l.logErr(DefaultGroupId, err)
+	return err
+}
+
+// Errorsf logs a message to default group at error level and returns it, so
+// that it can also be returned up the stack without formatting it twice:
+//
+//	return errors.New(trace.Errorsf("open %s: %v", path, err))
+//
+// Similar to fmt.Sprintf(...)
+func Errorsf(format string, a ...interface{}) string {
+	msg := fmt.Sprintf(format, a...)
+	std.log(DefaultGroupId, LevelError, "", msg)
+	return msg
+}
+
+// ErrorfE logs a message to default group at error level like ErrorErr and
+// returns it as an error. Similar to fmt.Errorf(...), so %w wraps an error:
+//
+//	return trace.ErrorfE("open %s: %w", path, err)
+func ErrorfE(format string, a ...interface{}) error {
+	err := fmt.Errorf(format, a...)
+	std.logErr(DefaultGroupId, err)
+	return err
+}
@@ -0,0 +1,155 @@
+package trace
+
+// Fields are key/value pairs attached to a log message
+type Fields map[string]interface{}
+
+// FieldLogger logs messages that carry a fixed set of fields. Create one with WithFields.
+type FieldLogger struct {
+	logger *Logger
+	fields Fields
+}
+
+// WithFields returns a FieldLogger that attaches the given fields to every message.
+// The fields are copied, so the map may be reused after the call.
+func (l *Logger) WithFields(fields Fields) FieldLogger {
+	copied := make(Fields, len(fields))
+	for key, value := range fields {
+		copied[key] = value
+	}
+	return FieldLogger{logger: l, fields: copied}
+}
+
+// WithFields returns a FieldLogger that attaches the given fields to every message.
+// The fields are copied, so the map may be reused after the call.
+func WithFields(fields Fields) FieldLogger {
+	return std.WithFields(fields)
+}
+
+type cmdSetGlobalFields struct {
+	fields Fields
+}
+
+func (c *cmdSetGlobalFields) do(l *Logger) {
+	l.globalFields = c.fields
+}
+
+// SetGlobalFields sets fields, such as the service name, version, or region,
+// that are attached to every message of every group in all formats. Fields of
+// the message with the same key take precedence. The fields are copied, so the
+// map may be reused after the call. A nil or empty map removes them.
+func (l *Logger) SetGlobalFields(fields map[string]interface{}) {
+	var copied Fields
+	if len(fields) > 0 {
+		copied = make(Fields, len(fields))
+		for key, value := range fields {
+			copied[key] = value
+		}
+	}
+	l.enqueue(&cmdSetGlobalFields{copied})
+}
+
+// SetGlobalFields sets fields, such as the service name, version, or region,
+// that are attached to every message of every group in all formats. Fields of
+// the message with the same key take precedence. The fields are copied, so the
+// map may be reused after the call. A nil or empty map removes them.
+func SetGlobalFields(fields map[string]interface{}) {
+	std.SetGlobalFields(fields)
+}
+
+// addGlobalFields is a helper function for merging the fields set with
+// SetGlobalFields into a message. The message's fields may be shared with a
+// FieldLogger, so they are copied rather than changed.
+func (l *Logger) addGlobalFields(m *msgData) {
+	if l.globalFields == nil {
+		return
+	}
+
+	merged := make(Fields, len(l.globalFields)+len(m.fields))
+	for key, value := range l.globalFields {
+		merged[key] = value
+	}
+	for key, value := range m.fields {
+		merged[key] = value
+	}
+	m.fields = merged
+}
+
+// Error logs a message to default group at error level. Similar to fmt.Print(...)
+func (f FieldLogger) Error(a ...interface{}) {
+	f.logger.logFields(0, LevelError, f.fields, "", a...)
+}
+
+// Errorf logs a message to default group at error level. Similar to fmt.Printf(...)
+func (f FieldLogger) Errorf(format string, a ...interface{}) {
+	f.logger.logFields(0, LevelError, f.fields, format, a...)
+}
+
+// Errorg logs a message to given group at error level. Similar to fmt.Print(...)
+func (f FieldLogger) Errorg(group int, a ...interface{}) {
+	f.logger.logFields(group, LevelError, f.fields, "", a...)
+}
+
+// Errorgf logs a message to given group at error level. Similar to fmt.Printf(...)
+func (f FieldLogger) Errorgf(group int, format string, a ...interface{}) {
+	f.logger.logFields(group, LevelError, f.fields, format, a...)
+}
+
+// Info logs a message to default group at info level. Similar to fmt.Print(...)
+func (f FieldLogger) Info(a ...interface{}) {
+	f.logger.logFields(0, LevelInfo, f.fields, "", a...)
+}
+
+// Infof logs a message to default group at info level. Similar to fmt.Printf(...)
+func (f FieldLogger) Infof(format string, a ...interface{}) {
+	f.logger.logFields(0, LevelInfo, f.fields, format, a...)
+}
+
+// Infog logs a message to given group at info level. Similar to fmt.Print(...)
+func (f FieldLogger) Infog(group int, a ...interface{}) {
+	f.logger.logFields(group, LevelInfo, f.fields, "", a...)
+}
+
+// Infogf logs a message to given group at info level. Similar to fmt.Printf(...)
+func (f FieldLogger) Infogf(group int, format string, a ...interface{}) {
+	f.logger.logFields(group, LevelInfo, f.fields, format, a...)
+}
+
+// Trace logs a message to default group at trace level. Similar to fmt.Print(...)
+func (f FieldLogger) Trace(a ...interface{}) {
+	f.logger.logFields(0, LevelTrace, f.fields, "", a...)
+}
+
+// Tracef logs a message to default group at trace level. Similar to fmt.Printf(...)
+func (f FieldLogger) Tracef(format string, a ...interface{}) {
+	f.logger.logFields(0, LevelTrace, f.fields, format, a...)
+}
+
+// Traceg logs a message to given group at trace level. Similar to fmt.Print(...)
+func (f FieldLogger) Traceg(group int, a ...interface{}) {
+	f.logger.logFields(group, LevelTrace, f.fields, "", a...)
+}
+
+// Tracegf logs a message to given group at trace level. Similar to fmt.Printf(...)
+func (f FieldLogger) Tracegf(group int, format string, a ...interface{}) {
+	f.logger.logFields(group, LevelTrace, f.fields, format, a...)
+}
+
+// Warn logs a message to default group at warn level. Similar to fmt.Print(...)
+func (f FieldLogger) Warn(a ...interface{}) {
+	f.logger.logFields(0, LevelWarn, f.fields, "", a...)
+}
+
+// Warnf logs a message to default group at warn level. Similar to fmt.Printf(...)
+func (f FieldLogger) Warnf(format string, a ...interface{}) {
+	f.logger.logFields(0, LevelWarn, f.fields, format, a...)
+}
+
+// Warng logs a message to given group at warn level. Similar to fmt.Print(...)
+func (f FieldLogger) Warng(group int, a ...interface{}) {
+	f.logger.logFields(group, LevelWarn, f.fields, "", a...)
+}
+
+// Warngf logs a message to given group at warn level. Similar to fmt.Printf(...)
+func (f FieldLogger) Warngf(group int, format string, a ...interface{}) {
+	f.logger.logFields(group, LevelWarn, f.fields, format, a...)
+}
@@ -0,0 +1,43 @@
+package trace
+
+// logForced is a helper function for processing log requests that bypass the
+// level and group checks
+func (l *Logger) logForced(group int, lvl Level, format string, a ...interface{}) {
+	l.send(lvl, msgData{group: group, forced: true}, format, a...)
+}
+
+// ForceInfo logs a message to the group at info level even if the group is
+// disabled or its level, or SetMinLevel, is above info, for messages that must
+// always appear such as a startup banner. It bypasses all level and group
+// filtering, so keep it for rare messages. Only SetEnabled(false) turns it
+// off. Similar to fmt.Print(...)
+func (l *Logger) ForceInfo(group int, a ...interface{}) {
+	l.logForced(group, LevelInfo, "", a...)
+}
+
+// ForceInfof logs a message to the group at info level even if the group is
+// disabled or its level, or SetMinLevel, is above info, for messages that must
+// always appear such as a startup banner. It bypasses all level and group
+// filtering, so keep it for rare messages. Only SetEnabled(false) turns it
+// off. Similar to fmt.Printf(...)
+func (l *Logger) ForceInfof(group int, format string, a ...interface{}) {
+	l.logForced(group, LevelInfo, format, a...)
+}
+
+// ForceInfo logs a message to the group at info level even if the group is
+// disabled or its level, or SetMinLevel, is above info, for messages that must
+// always appear such as a startup banner. It bypasses all level and group
+// filtering, so keep it for rare messages. Only SetEnabled(false) turns it
+// off. Similar to fmt.Print(...)
+func ForceInfo(group int, a ...interface{}) {
+	std.logForced(group, LevelInfo, "", a...)
+}
+
+// ForceInfof logs a message to the group at info level even if the group is
+// disabled or its level, or SetMinLevel, is above info, for messages that must
+// always appear such as a startup banner. It bypasses all level and group
+// filtering, so keep it for rare messages. Only SetEnabled(false) turns it
+// off. Similar to fmt.Printf(...)
+func ForceInfof(group int, format string, a ...interface{}) {
+	std.logForced(group, LevelInfo, format, a...)
+}
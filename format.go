@@ -0,0 +1,657 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Format selects the layout of every log line
+type Format int
+
+const (
+	// FormatText writes human readable lines. This is the default
+	FormatText Format = iota
+
+	// FormatJSON writes one JSON object per line
+	FormatJSON
+
+	// FormatLogfmt writes key=value pairs as understood by Loki and other logfmt parsers
+	FormatLogfmt
+
+	// FormatLengthPrefixed writes each FormatText line, without its newline, as
+	// a frame preceded by its length in 4 bytes, big-endian. Messages can then
+	// contain newlines. Read the frames with ReadFrame
+	FormatLengthPrefixed
+)
+
+// DefaultTimeFormat is the layout of timestamps in FormatText unless changed with SetTimeFormat
+const DefaultTimeFormat = "2006-1-2 15:04:05.000000"
+
+// Escapes the control characters that would split or misalign a FormatText line
+var controlEscaper = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// Keys used by FormatJSON and FormatLogfmt. Fields with the same name are prefixed with "fields."
+var reservedKeys = map[string]bool{"time": true, "prefix": true, "seq": true, "chain": true, "level": true, "group": true, "host": true, "pid": true, "id": true, "caller": true, "msg": true}
+
+type cmdSetFormat struct {
+	format Format
+}
+
+func (c *cmdSetFormat) do(l *Logger) {
+	l.outputFormat = c.format
+}
+
+// SetFormat sets the layout of every log line.
+//
+// FormatText writes lines like:
+//
+//	2006-1-2 15:04:05.000000 INFO [audit] the message user=bob
+//
+// FormatJSON writes lines like:
+//
+//	{"time":"2006-01-02T15:04:05.123456789Z","level":"info","group":"audit","msg":"the message","user":"bob"}
+//
+// FormatLogfmt writes lines like:
+//
+//	time=2006-01-02T15:04:05.123456789Z level=info group=audit msg="the message" user=bob
+//
+// Their time is written with time.RFC3339Nano, so trailing zeros of the
+// fraction are dropped and a time on a whole second has none. The group key is
+// omitted for the default group. FormatLengthPrefixed writes FormatText lines
+// as binary frames for socket transports.
+func (l *Logger) SetFormat(f Format) {
+	l.enqueue(&cmdSetFormat{f})
+}
+
+// SetFormat sets the layout of every log line.
+//
+// FormatText writes lines like:
+//
+//	2006-1-2 15:04:05.000000 INFO [audit] the message user=bob
+//
+// FormatJSON writes lines like:
+//
+//	{"time":"2006-01-02T15:04:05.123456789Z","level":"info","group":"audit","msg":"the message","user":"bob"}
+//
+// FormatLogfmt writes lines like:
+//
+//	time=2006-01-02T15:04:05.123456789Z level=info group=audit msg="the message" user=bob
+//
+// Their time is written with time.RFC3339Nano, so trailing zeros of the
+// fraction are dropped and a time on a whole second has none. The group key is
+// omitted for the default group. FormatLengthPrefixed writes FormatText lines
+// as binary frames for socket transports.
+func SetFormat(f Format) {
+	std.SetFormat(f)
+}
+
+type cmdSetTimeFormat struct {
+	layout string
+}
+
+func (c *cmdSetTimeFormat) do(l *Logger) {
+	l.timeLayout = c.layout
+}
+
+// SetTimeFormat sets the layout, as understood by time.Time.Format, of the
+// timestamp in FormatText. An empty layout restores DefaultTimeFormat.
+// FormatJSON always uses RFC 3339.
+func (l *Logger) SetTimeFormat(layout string) {
+	if layout == "" {
+		layout = DefaultTimeFormat
+	}
+	l.enqueue(&cmdSetTimeFormat{layout})
+}
+
+// SetTimeFormat sets the layout, as understood by time.Time.Format, of the
+// timestamp in FormatText. An empty layout restores DefaultTimeFormat.
+// FormatJSON always uses RFC 3339.
+func SetTimeFormat(layout string) {
+	std.SetTimeFormat(layout)
+}
+
+// Precision selects the number of fractional second digits of FormatText timestamps
+type Precision int
+
+const (
+	// PrecisionSeconds writes whole seconds
+	PrecisionSeconds Precision = iota
+
+	// PrecisionMillis writes 3 fractional digits
+	PrecisionMillis
+
+	// PrecisionMicros writes 6 fractional digits, as DefaultTimeFormat does
+	PrecisionMicros
+
+	// PrecisionNanos writes 9 fractional digits
+	PrecisionNanos
+)
+
+// Fractional second suffixes of DefaultTimeFormat for each Precision
+var precisionSuffixes = [...]string{
+	PrecisionSeconds: "",
+	PrecisionMillis:  ".000",
+	PrecisionMicros:  ".000000",
+	PrecisionNanos:   ".000000000",
+}
+
+// SetTimePrecision sets the number of fractional second digits of FormatText
+// timestamps, keeping the rest of DefaultTimeFormat. It replaces a layout set
+// with SetTimeFormat, and SetTimeFormat replaces it in turn. The default is
+// PrecisionMicros.
+func (l *Logger) SetTimePrecision(p Precision) {
+	if p < PrecisionSeconds || p > PrecisionNanos {
+		p = PrecisionMicros
+	}
+	l.enqueue(&cmdSetTimeFormat{strings.TrimSuffix(DefaultTimeFormat, ".000000") + precisionSuffixes[p]})
+}
+
+// SetTimePrecision sets the number of fractional second digits of FormatText
+// timestamps, keeping the rest of DefaultTimeFormat. It replaces a layout set
+// with SetTimeFormat, and SetTimeFormat replaces it in turn. The default is
+// PrecisionMicros.
+func SetTimePrecision(p Precision) {
+	std.SetTimePrecision(p)
+}
+
+type cmdSetTimeZone struct {
+	loc *time.Location
+}
+
+func (c *cmdSetTimeZone) do(l *Logger) {
+	l.timeLocation = c.loc
+}
+
+// SetTimeZone sets the location timestamps are written in. Timestamps are
+// written in UTC by default; use SetTimeZone(time.Local) for local time.
+// A nil location restores UTC.
+func (l *Logger) SetTimeZone(loc *time.Location) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	l.enqueue(&cmdSetTimeZone{loc})
+}
+
+// SetTimeZone sets the location timestamps are written in. Timestamps are
+// written in UTC by default; use SetTimeZone(time.Local) for local time.
+// A nil location restores UTC.
+func SetTimeZone(loc *time.Location) {
+	std.SetTimeZone(loc)
+}
+
+type cmdSetTimestamps struct {
+	on bool
+}
+
+func (c *cmdSetTimestamps) do(l *Logger) {
+	l.timestamps = c.on
+}
+
+// SetTimestamps turns the timestamp at the start of every line on or off.
+// Turn it off when the collector, such as journald or Docker, already
+// timestamps each line. With FormatJSON the time key is omitted. Timestamps
+// are on by default.
+func (l *Logger) SetTimestamps(on bool) {
+	l.enqueue(&cmdSetTimestamps{on})
+}
+
+// SetTimestamps turns the timestamp at the start of every line on or off.
+// Turn it off when the collector, such as journald or Docker, already
+// timestamps each line. With FormatJSON the time key is omitted. Timestamps
+// are on by default.
+func SetTimestamps(on bool) {
+	std.SetTimestamps(on)
+}
+
+// Entry is a log message as passed to a formatter set with SetFormatter
+type Entry struct {
+	// Group is the ID of the group the message was logged to
+	Group int
+
+	// GroupName is the name of the group, empty for the default group
+	GroupName string
+
+	// Prefix is the group's prefix set with SetGroupPrefix, empty for none
+	Prefix string
+
+	// Seq is the number of the message when EnableSequence is on, zero otherwise
+	Seq uint64
+
+	Level Level
+
+	// Host is the hostname when EnableHostname is on, empty otherwise
+	Host string
+
+	// PID is the process ID when EnablePID is on, zero otherwise
+	PID int
+
+	// Time is when the message was logged, in the location set with SetTimeZone
+	Time time.Time
+
+	// ID is the request or correlation ID, empty for none
+	ID string
+
+	// Caller is the source file and line, or function, as set with SetCallerMode
+	Caller string
+
+	// Message is the formatted message
+	Message string
+
+	// Fields are the fields attached with WithFields. Must not be modified
+	Fields Fields
+
+	// Err is the error logged with ErrorErr or ErrorgErr, nil otherwise
+	Err error
+}
+
+type cmdSetFormatter struct {
+	formatter func(e Entry) []byte
+}
+
+func (c *cmdSetFormatter) do(l *Logger) {
+	l.formatter = c.formatter
+}
+
+// SetFormatter replaces the layout of every log line with the given function.
+// The returned bytes are written to the group's outputs as they are, so they
+// should end with a newline. The formatter runs on the log goroutine and
+// overrides SetFormat, SetTimeFormat, SetTimestamps and SetColor. Wrap
+// TextFormatter to extend the default layout. A nil formatter restores SetFormat.
+func (l *Logger) SetFormatter(formatter func(e Entry) []byte) {
+	l.enqueue(&cmdSetFormatter{formatter})
+}
+
+// SetFormatter replaces the layout of every log line with the given function.
+// The returned bytes are written to the group's outputs as they are, so they
+// should end with a newline. The formatter runs on the log goroutine and
+// overrides SetFormat, SetTimeFormat, SetTimestamps and SetColor. Wrap
+// TextFormatter to extend the default layout. A nil formatter restores SetFormat.
+func SetFormatter(formatter func(e Entry) []byte) {
+	std.SetFormatter(formatter)
+}
+
+// TextFormatter renders an Entry in FormatText with DefaultTimeFormat, for
+// formatters set with SetFormatter that extend the default layout.
+func TextFormatter(e Entry) []byte {
+	return writeText(e, e.Time.Format(DefaultTimeFormat), " ", false)
+}
+
+// entry is a helper function for converting a log message to an Entry
+func (l *Logger) entry(lvl Level, m *msgData) Entry {
+	g := l.groups[m.group]
+	return Entry{
+		Group:     m.group,
+		GroupName: g.name,
+		Prefix:    g.prefix,
+		Seq:       m.seq,
+		Level:     lvl,
+		Host:      l.host(),
+		PID:       l.pid(),
+		Time:      m.t.In(l.timeLocation),
+		ID:        m.id,
+		Caller:    m.caller,
+		Message:   m.msg,
+		Fields:    m.fields,
+		Err:       m.err,
+	}
+}
+
+type cmdSetEscapeControl struct {
+	on bool
+}
+
+func (c *cmdSetEscapeControl) do(l *Logger) {
+	l.escapeControl = c.on
+}
+
+// SetEscapeControl turns on or off escaping newlines, carriage returns, and
+// tabs in FormatText messages and field values as \n, \r, and \t, so that every
+// message stays on a single line for line oriented parsers. FormatJSON and FormatLogfmt always
+// escape them. It is off by default.
+func (l *Logger) SetEscapeControl(on bool) {
+	l.enqueue(&cmdSetEscapeControl{on})
+}
+
+// SetEscapeControl turns on or off escaping newlines, carriage returns, and
+// tabs in FormatText messages and field values as \n, \r, and \t, so that every
+// message stays on a single line for line oriented parsers. FormatJSON and FormatLogfmt always
+// escape them. It is off by default.
+func SetEscapeControl(on bool) {
+	std.SetEscapeControl(on)
+}
+
+type cmdSetFieldSeparator struct {
+	sep string
+}
+
+func (c *cmdSetFieldSeparator) do(l *Logger) {
+	l.fieldSeparator = c.sep
+}
+
+// SetFieldSeparator sets the separator between the parts of FormatText lines:
+// the timestamp, prefix, sequence number, level, [group], [host=x], [pid=x],
+// [id=x], caller, message, and each key=value field. A tab, for example, gives lines that
+// spreadsheets and awk split easily. The default timestamp layout contains a
+// space, which SetTimeFormat can remove. An empty separator restores the
+// default, a single space.
+func (l *Logger) SetFieldSeparator(sep string) {
+	l.enqueue(&cmdSetFieldSeparator{sep})
+}
+
+// SetFieldSeparator sets the separator between the parts of FormatText lines:
+// the timestamp, prefix, sequence number, level, [group], [host=x], [pid=x],
+// [id=x], caller, message, and each key=value field. A tab, for example, gives lines that
+// spreadsheets and awk split easily. The default timestamp layout contains a
+// space, which SetTimeFormat can remove. An empty separator restores the
+// default, a single space.
+func SetFieldSeparator(sep string) {
+	std.SetFieldSeparator(sep)
+}
+
+// TimeMode selects how timestamps are written in FormatText
+type TimeMode int
+
+const (
+	// TimeAbsolute writes the date and time using the SetTimeFormat layout. This is the default
+	TimeAbsolute TimeMode = iota
+
+	// TimeRelative writes the time elapsed since the program started, like +00:00:01.234567
+	TimeRelative
+)
+
+// When the program started, for TimeRelative
+var startTime = time.Now()
+
+type cmdSetTimeMode struct {
+	mode TimeMode
+}
+
+func (c *cmdSetTimeMode) do(l *Logger) {
+	l.timeMode = c.mode
+}
+
+// SetTimeMode sets how timestamps are written in FormatText. TimeRelative
+// writes the time elapsed since the program started instead of the date and
+// time, which shows how long after startup each event happened when
+// benchmarking or profiling. FormatJSON and FormatLogfmt always write the date
+// and time. The default is TimeAbsolute.
+func (l *Logger) SetTimeMode(mode TimeMode) {
+	l.enqueue(&cmdSetTimeMode{mode})
+}
+
+// SetTimeMode sets how timestamps are written in FormatText. TimeRelative
+// writes the time elapsed since the program started instead of the date and
+// time, which shows how long after startup each event happened when
+// benchmarking or profiling. FormatJSON and FormatLogfmt always write the date
+// and time. The default is TimeAbsolute.
+func SetTimeMode(mode TimeMode) {
+	std.SetTimeMode(mode)
+}
+
+// relativeTime is a helper function for formatting the time elapsed since startTime as +hh:mm:ss.ffffff
+func relativeTime(t time.Time) string {
+	d := t.Sub(startTime)
+	if d < 0 {
+		d = 0
+	}
+
+	micros := d.Microseconds()
+	return fmt.Sprintf("+%02d:%02d:%02d.%06d", micros/3600e6, micros/60e6%60, micros/1e6%60, micros%1e6)
+}
+
+// formatText is a helper function for rendering a log message in FormatText
+func (l *Logger) formatText(lvl Level, m *msgData, color bool) []byte {
+	var stamp string
+	if l.timestamps {
+		if l.timeMode == TimeRelative {
+			stamp = relativeTime(m.t)
+		} else {
+			stamp = m.t.In(l.timeLocation).Format(l.timeLayout)
+		}
+	}
+
+	e := l.entry(lvl, m)
+	if l.errorChain && m.err != nil {
+		e.Message += " (chain: " + textChain(errorChain(m.err)) + ")"
+	}
+	if l.escapeControl {
+		e.Message = controlEscaper.Replace(e.Message)
+		e.Fields = escapeFields(e.Fields)
+	}
+
+	sep := l.fieldSeparator
+	if sep == "" {
+		sep = " "
+	}
+	return writeText(e, stamp, sep, color)
+}
+
+// writeText is a helper function for rendering an Entry in FormatText with the
+// formatted timestamp and parts separated by sep. An empty stamp omits the timestamp.
+func writeText(e Entry, stamp, sep string, color bool) []byte {
+	var b strings.Builder
+
+	if stamp != "" {
+		b.WriteString(stamp + sep)
+	}
+	if e.Prefix != "" {
+		b.WriteString(e.Prefix + sep)
+	}
+	if e.Seq != 0 {
+		fmt.Fprintf(&b, "#%06d%s", e.Seq, sep)
+	}
+	if color {
+		b.WriteString(levelColors[e.Level.base()] + e.Level.String() + colorReset)
+	} else {
+		b.WriteString(e.Level.String())
+	}
+	if e.Group != DefaultGroupId {
+		b.WriteString(sep + "[" + e.GroupName + "]")
+	}
+	if e.Host != "" {
+		b.WriteString(sep + "[host=" + e.Host + "]")
+	}
+	if e.PID != 0 {
+		b.WriteString(sep + "[pid=" + strconv.Itoa(e.PID) + "]")
+	}
+	if e.ID != "" {
+		b.WriteString(sep + "[id=" + e.ID + "]")
+	}
+	if e.Caller != "" {
+		b.WriteString(sep + e.Caller)
+	}
+	b.WriteString(sep + e.Message)
+	b.WriteString(textFields(e.Fields, sep))
+	b.WriteByte('\n')
+
+	return []byte(b.String())
+}
+
+// textFields is a helper function for rendering fields as key=value pairs, each preceded by sep
+func textFields(fields Fields, sep string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, key := range sortedKeys(fields) {
+		fmt.Fprintf(&b, "%s%s=%v", sep, key, fields[key])
+	}
+	return b.String()
+}
+
+// escapeFields is a helper function for copying fields with the control characters
+// of their values escaped. The fields of the message are shared, so they are not changed.
+func escapeFields(fields Fields) Fields {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	escaped := make(Fields, len(fields))
+	for key, value := range fields {
+		escaped[key] = controlEscaper.Replace(fmt.Sprint(value))
+	}
+	return escaped
+}
+
+// formatJSON is a helper function for rendering a log message in FormatJSON
+func (l *Logger) formatJSON(lvl Level, m *msgData) []byte {
+	var b bytes.Buffer
+
+	b.WriteByte('{')
+	if l.timestamps {
+		b.WriteString(`"time":`)
+		writeJSONValue(&b, m.t.In(l.timeLocation).Format(time.RFC3339Nano))
+		b.WriteByte(',')
+	}
+	if prefix := l.groups[m.group].prefix; prefix != "" {
+		b.WriteString(`"prefix":`)
+		writeJSONValue(&b, prefix)
+		b.WriteByte(',')
+	}
+	if m.seq != 0 {
+		b.WriteString(`"seq":`)
+		writeJSONValue(&b, m.seq)
+		b.WriteByte(',')
+	}
+	b.WriteString(`"level":`)
+	writeJSONValue(&b, strings.ToLower(lvl.String()))
+	if m.group != DefaultGroupId {
+		b.WriteString(`,"group":`)
+		writeJSONValue(&b, l.groups[m.group].name)
+	}
+	if host := l.host(); host != "" {
+		b.WriteString(`,"host":`)
+		writeJSONValue(&b, host)
+	}
+	if pid := l.pid(); pid != 0 {
+		b.WriteString(`,"pid":`)
+		writeJSONValue(&b, pid)
+	}
+	if m.id != "" {
+		b.WriteString(`,"id":`)
+		writeJSONValue(&b, m.id)
+	}
+	if m.caller != "" {
+		b.WriteString(`,"caller":`)
+		writeJSONValue(&b, m.caller)
+	}
+	b.WriteString(`,"msg":`)
+	writeJSONValue(&b, m.msg)
+	if l.errorChain && m.err != nil {
+		b.WriteString(`,"chain":`)
+		writeJSONValue(&b, errorChain(m.err))
+	}
+
+	for _, key := range sortedKeys(m.fields) {
+		name := key
+		if reservedKeys[name] {
+			name = "fields." + name
+		}
+		b.WriteByte(',')
+		writeJSONValue(&b, name)
+		b.WriteByte(':')
+		writeJSONValue(&b, m.fields[key])
+	}
+
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+// formatLogfmt is a helper function for rendering a log message in FormatLogfmt
+func (l *Logger) formatLogfmt(lvl Level, m *msgData) []byte {
+	var b strings.Builder
+
+	if l.timestamps {
+		writeLogfmtPair(&b, "time", m.t.In(l.timeLocation).Format(time.RFC3339Nano))
+	}
+	if prefix := l.groups[m.group].prefix; prefix != "" {
+		writeLogfmtPair(&b, "prefix", prefix)
+	}
+	if m.seq != 0 {
+		writeLogfmtPair(&b, "seq", strconv.FormatUint(m.seq, 10))
+	}
+	writeLogfmtPair(&b, "level", strings.ToLower(lvl.String()))
+	if m.group != DefaultGroupId {
+		writeLogfmtPair(&b, "group", l.groups[m.group].name)
+	}
+	if host := l.host(); host != "" {
+		writeLogfmtPair(&b, "host", host)
+	}
+	if pid := l.pid(); pid != 0 {
+		writeLogfmtPair(&b, "pid", strconv.Itoa(pid))
+	}
+	if m.id != "" {
+		writeLogfmtPair(&b, "id", m.id)
+	}
+	if m.caller != "" {
+		writeLogfmtPair(&b, "caller", m.caller)
+	}
+	writeLogfmtPair(&b, "msg", m.msg)
+	if l.errorChain && m.err != nil {
+		writeLogfmtPair(&b, "chain", textChain(errorChain(m.err)))
+	}
+
+	for _, key := range sortedKeys(m.fields) {
+		name := key
+		if reservedKeys[name] {
+			name = "fields." + name
+		}
+		writeLogfmtPair(&b, name, fmt.Sprint(m.fields[key]))
+	}
+
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+// writeLogfmtPair is a helper function for writing a key=value pair. Values
+// that are empty or contain spaces, quotes, equals signs, or control characters
+// are quoted and escaped.
+func writeLogfmtPair(b *strings.Builder, key, value string) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+
+	if value == "" || strings.IndexFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == 0x7f || !unicode.IsPrint(r)
+	}) >= 0 {
+		b.WriteString(strconv.Quote(value))
+		return
+	}
+	b.WriteString(value)
+}
+
+// writeJSONValue is a helper function for encoding a single JSON value. Errors
+// and fmt.Stringers that do not implement json.Marshaler, and values that
+// cannot be encoded, are written as their fmt.Sprint string.
+func writeJSONValue(b *bytes.Buffer, v interface{}) {
+	switch v.(type) {
+	case json.Marshaler:
+	case error, fmt.Stringer:
+		v = fmt.Sprint(v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	b.Write(data)
+}
+
+// sortedKeys is a helper function for iterating fields in a stable order
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,35 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// frame is a helper function for converting a FormatText line to a FormatLengthPrefixed frame
+func frame(line []byte) []byte {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	framed := make([]byte, 4+len(line))
+	binary.BigEndian.PutUint32(framed, uint32(len(line)))
+	copy(framed[4:], line)
+	return framed
+}
+
+// ReadFrame reads the next line written in FormatLengthPrefixed from r, such
+// as a socket the lines are sent over. It returns io.EOF when r ends between
+// frames, and io.ErrUnexpectedEOF when it ends within one.
+func ReadFrame(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+
+	line := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := io.ReadFull(r, line); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return line, nil
+}
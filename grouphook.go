@@ -0,0 +1,31 @@
+package trace
+
+type cmdSetGroupCreatedHook struct {
+	hook func(info GroupInfo)
+}
+
+func (c *cmdSetGroupCreatedHook) do(l *Logger) {
+	l.groupCreatedHook = c.hook
+}
+
+// SetGroupCreatedHook sets a function called with the ID, name and enabled
+// state of every group registered afterwards, so that instrumentation such as
+// per group counters can be set up as groups appear. Groups registered before
+// the call can be found with ListGroups. The hook runs on the goroutine
+// registering the group, before RegisterGroup returns, so it may query or log
+// to the group, but it may be called concurrently when groups are registered
+// from several goroutines. A nil hook removes it.
+func (l *Logger) SetGroupCreatedHook(hook func(info GroupInfo)) {
+	l.enqueue(&cmdSetGroupCreatedHook{hook})
+}
+
+// SetGroupCreatedHook sets a function called with the ID, name and enabled
+// state of every group registered afterwards, so that instrumentation such as
+// per group counters can be set up as groups appear. Groups registered before
+// the call can be found with ListGroups. The hook runs on the goroutine
+// registering the group, before RegisterGroup returns, so it may query or log
+// to the group, but it may be called concurrently when groups are registered
+// from several goroutines. A nil hook removes it.
+func SetGroupCreatedHook(hook func(info GroupInfo)) {
+	std.SetGroupCreatedHook(hook)
+}
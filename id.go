@@ -0,0 +1,166 @@
+package trace
+
+// logID is a helper function for processing log requests tagged with a correlation ID
+func (l *Logger) logID(id string, group int, lvl Level, format string, a ...interface{}) {
+	l.send(lvl, msgData{group: group, id: id}, format, a...)
+}
+
+// ErrorID logs a message tagged with id to default group at error level. Similar to fmt.Print(...)
+func (l *Logger) ErrorID(id string, a ...interface{}) {
+	l.logID(id, 0, LevelError, "", a...)
+}
+
+// ErrorfID logs a message tagged with id to default group at error level. Similar to fmt.Printf(...)
+func (l *Logger) ErrorfID(id string, format string, a ...interface{}) {
+	l.logID(id, 0, LevelError, format, a...)
+}
+
+// ErrorgID logs a message tagged with id to given group at error level. Similar to fmt.Print(...)
+func (l *Logger) ErrorgID(id string, group int, a ...interface{}) {
+	l.logID(id, group, LevelError, "", a...)
+}
+
+// ErrorgfID logs a message tagged with id to given group at error level. Similar to fmt.Printf(...)
+func (l *Logger) ErrorgfID(id string, group int, format string, a ...interface{}) {
+	l.logID(id, group, LevelError, format, a...)
+}
+
+// InfoID logs a message tagged with id to default group at info level. Similar to fmt.Print(...)
+func (l *Logger) InfoID(id string, a ...interface{}) {
+	l.logID(id, 0, LevelInfo, "", a...)
+}
+
+// InfofID logs a message tagged with id to default group at info level. Similar to fmt.Printf(...)
+func (l *Logger) InfofID(id string, format string, a ...interface{}) {
+	l.logID(id, 0, LevelInfo, format, a...)
+}
+
+// InfogID logs a message tagged with id to given group at info level. Similar to fmt.Print(...)
+func (l *Logger) InfogID(id string, group int, a ...interface{}) {
+	l.logID(id, group, LevelInfo, "", a...)
+}
+
+// InfogfID logs a message tagged with id to given group at info level. Similar to fmt.Printf(...)
+func (l *Logger) InfogfID(id string, group int, format string, a ...interface{}) {
+	l.logID(id, group, LevelInfo, format, a...)
+}
+
+// TraceID logs a message tagged with id to default group at trace level. Similar to fmt.Print(...)
+func (l *Logger) TraceID(id string, a ...interface{}) {
+	l.logID(id, 0, LevelTrace, "", a...)
+}
+
+// TracefID logs a message tagged with id to default group at trace level. Similar to fmt.Printf(...)
+func (l *Logger) TracefID(id string, format string, a ...interface{}) {
+	l.logID(id, 0, LevelTrace, format, a...)
+}
+
+// TracegID logs a message tagged with id to given group at trace level. Similar to fmt.Print(...)
+func (l *Logger) TracegID(id string, group int, a ...interface{}) {
+	l.logID(id, group, LevelTrace, "", a...)
+}
+
+// TracegfID logs a message tagged with id to given group at trace level. Similar to fmt.Printf(...)
+func (l *Logger) TracegfID(id string, group int, format string, a ...interface{}) {
+	l.logID(id, group, LevelTrace, format, a...)
+}
+
+// WarnID logs a message tagged with id to default group at warn level. Similar to fmt.Print(...)
+func (l *Logger) WarnID(id string, a ...interface{}) {
+	l.logID(id, 0, LevelWarn, "", a...)
+}
+
+// WarnfID logs a message tagged with id to default group at warn level. Similar to fmt.Printf(...)
+func (l *Logger) WarnfID(id string, format string, a ...interface{}) {
+	l.logID(id, 0, LevelWarn, format, a...)
+}
+
+// WarngID logs a message tagged with id to given group at warn level. Similar to fmt.Print(...)
+func (l *Logger) WarngID(id string, group int, a ...interface{}) {
+	l.logID(id, group, LevelWarn, "", a...)
+}
+
+// WarngfID logs a message tagged with id to given group at warn level. Similar to fmt.Printf(...)
+func (l *Logger) WarngfID(id string, group int, format string, a ...interface{}) {
+	l.logID(id, group, LevelWarn, format, a...)
+}
+
+// ErrorID logs a message tagged with id to default group at error level. Similar to fmt.Print(...)
+func ErrorID(id string, a ...interface{}) {
+	std.logID(id, 0, LevelError, "", a...)
+}
+
+// ErrorfID logs a message tagged with id to default group at error level. Similar to fmt.Printf(...)
+func ErrorfID(id string, format string, a ...interface{}) {
+	std.logID(id, 0, LevelError, format, a...)
+}
+
+// ErrorgID logs a message tagged with id to given group at error level. Similar to fmt.Print(...)
+func ErrorgID(id string, group int, a ...interface{}) {
+	std.logID(id, group, LevelError, "", a...)
+}
+
+// ErrorgfID logs a message tagged with id to given group at error level. Similar to fmt.Printf(...)
+func ErrorgfID(id string, group int, format string, a ...interface{}) {
+	std.logID(id, group, LevelError, format, a...)
+}
+
+// InfoID logs a message tagged with id to default group at info level. Similar to fmt.Print(...)
+func InfoID(id string, a ...interface{}) {
+	std.logID(id, 0, LevelInfo, "", a...)
+}
+
+// InfofID logs a message tagged with id to default group at info level. Similar to fmt.Printf(...)
+func InfofID(id string, format string, a ...interface{}) {
+	std.logID(id, 0, LevelInfo, format, a...)
+}
+
+// InfogID logs a message tagged with id to given group at info level. Similar to fmt.Print(...)
+func InfogID(id string, group int, a ...interface{}) {
+	std.logID(id, group, LevelInfo, "", a...)
+}
+
+// InfogfID logs a message tagged with id to given group at info level. Similar to fmt.Printf(...)
+func InfogfID(id string, group int, format string, a ...interface{}) {
+	std.logID(id, group, LevelInfo, format, a...)
+}
+
+// TraceID logs a message tagged with id to default group at trace level. Similar to fmt.Print(...)
+func TraceID(id string, a ...interface{}) {
+	std.logID(id, 0, LevelTrace, "", a...)
+}
+
+// TracefID logs a message tagged with id to default group at trace level. Similar to fmt.Printf(...)
+func TracefID(id string, format string, a ...interface{}) {
+	std.logID(id, 0, LevelTrace, format, a...)
+}
+
+// TracegID logs a message tagged with id to given group at trace level. Similar to fmt.Print(...)
+func TracegID(id string, group int, a ...interface{}) {
+	std.logID(id, group, LevelTrace, "", a...)
+}
+
+// TracegfID logs a message tagged with id to given group at trace level. Similar to fmt.Printf(...)
+func TracegfID(id string, group int, format string, a ...interface{}) {
+	std.logID(id, group, LevelTrace, format, a...)
+}
+
+// WarnID logs a message tagged with id to default group at warn level. Similar to fmt.Print(...)
+func WarnID(id string, a ...interface{}) {
+	std.logID(id, 0, LevelWarn, "", a...)
+}
+
+// WarnfID logs a message tagged with id to default group at warn level. Similar to fmt.Printf(...)
+func WarnfID(id string, format string, a ...interface{}) {
+	std.logID(id, 0, LevelWarn, format, a...)
+}
+
+// WarngID logs a message tagged with id to given group at warn level. Similar to fmt.Print(...)
+func WarngID(id string, group int, a ...interface{}) {
+	std.logID(id, group, LevelWarn, "", a...)
+}
+
+// WarngfID logs a message tagged with id to given group at warn level. Similar to fmt.Printf(...)
+func WarngfID(id string, group int, format string, a ...interface{}) {
+	std.logID(id, group, LevelWarn, format, a...)
+}
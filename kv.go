@@ -0,0 +1,95 @@
+package trace
+
+import "fmt"
+
+// BadKey is the key given to the last value of an odd number of key/value
+// arguments to the kv functions
+const BadKey = "!BADKEY"
+
+// kvFields is a helper function for converting alternating keys and values to
+// Fields. Keys that are not strings are formatted with fmt.Sprint.
+func kvFields(kv []interface{}) Fields {
+	fields := make(Fields, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 == len(kv) {
+			fields[BadKey] = kv[i]
+			break
+		}
+
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		fields[key] = kv[i+1]
+	}
+	return fields
+}
+
+// logKV is a helper function for processing log requests carrying key/value pairs
+func (l *Logger) logKV(group int, lvl Level, msg string, kv []interface{}) {
+	l.send(lvl, msgData{group: group, fields: kvFields(kv)}, "", msg)
+}
+
+// Errorkv logs a message to given group at error level with fields given as
+// alternating keys and values, such as Errorkv(g, "failed", "user", id). They
+// are written like the fields of WithFields. A final key without a value is
+// written as the value of BadKey.
+func (l *Logger) Errorkv(group int, msg string, kv ...interface{}) {
+	l.logKV(group, LevelError, msg, kv)
+}
+
+// Infokv logs a message to given group at info level with fields given as
+// alternating keys and values, such as Infokv(g, "login", "user", id). They
+// are written like the fields of WithFields. A final key without a value is
+// written as the value of BadKey.
+func (l *Logger) Infokv(group int, msg string, kv ...interface{}) {
+	l.logKV(group, LevelInfo, msg, kv)
+}
+
+// Tracekv logs a message to given group at trace level with fields given as
+// alternating keys and values, such as Tracekv(g, "query", "rows", n). They
+// are written like the fields of WithFields. A final key without a value is
+// written as the value of BadKey.
+func (l *Logger) Tracekv(group int, msg string, kv ...interface{}) {
+	l.logKV(group, LevelTrace, msg, kv)
+}
+
+// Warnkv logs a message to given group at warn level with fields given as
+// alternating keys and values, such as Warnkv(g, "retrying", "attempt", n).
+// They are written like the fields of WithFields. A final key without a value
+// is written as the value of BadKey.
+func (l *Logger) Warnkv(group int, msg string, kv ...interface{}) {
+	l.logKV(group, LevelWarn, msg, kv)
+}
+
+// Errorkv logs a message to given group at error level with fields given as
+// alternating keys and values, such as Errorkv(g, "failed", "user", id). They
+// are written like the fields of WithFields. A final key without a value is
+// written as the value of BadKey.
+func Errorkv(group int, msg string, kv ...interface{}) {
+	std.logKV(group, LevelError, msg, kv)
+}
+
+// Infokv logs a message to given group at info level with fields given as
+// alternating keys and values, such as Infokv(g, "login", "user", id). They
+// are written like the fields of WithFields. A final key without a value is
+// written as the value of BadKey.
+func Infokv(group int, msg string, kv ...interface{}) {
+	std.logKV(group, LevelInfo, msg, kv)
+}
+
+// Tracekv logs a message to given group at trace level with fields given as
+// alternating keys and values, such as Tracekv(g, "query", "rows", n). They
+// are written like the fields of WithFields. A final key without a value is
+// written as the value of BadKey.
+func Tracekv(group int, msg string, kv ...interface{}) {
+	std.logKV(group, LevelTrace, msg, kv)
+}
+
+// Warnkv logs a message to given group at warn level with fields given as
+// alternating keys and values, such as Warnkv(g, "retrying", "attempt", n).
+// They are written like the fields of WithFields. A final key without a value
+// is written as the value of BadKey.
+func Warnkv(group int, msg string, kv ...interface{}) {
+	std.logKV(group, LevelWarn, msg, kv)
+}
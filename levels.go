@@ -0,0 +1,115 @@
+package trace
+
+import "sync"
+
+// customLevel is a level added with RegisterLevel
+type customLevel struct {
+	name     string
+	severity Level
+}
+
+// Levels added with RegisterLevel, indexed by their Level minus LevelError+1
+var (
+	customLevelsMu sync.RWMutex
+	customLevels   []customLevel
+)
+
+// lookupCustomLevel is a helper function for finding a level added with RegisterLevel
+func lookupCustomLevel(l Level) (customLevel, bool) {
+	customLevelsMu.RLock()
+	defer customLevelsMu.RUnlock()
+
+	i := int(l - LevelError - 1)
+	if i < 0 || i >= len(customLevels) {
+		return customLevel{}, false
+	}
+	return customLevels[i], true
+}
+
+// severity is a helper function for the level messages of l are filtered as.
+// Built-in levels are their own severity.
+func (l Level) severity() Level {
+	if c, ok := lookupCustomLevel(l); ok {
+		return c.severity
+	}
+	return l
+}
+
+// base is a helper function for the built-in level whose outputs, color and
+// syslog priority messages of l use
+func (l Level) base() Level {
+	switch s := l.severity(); {
+	case s < LevelTrace:
+		return LevelTrace
+	case s > LevelError:
+		return LevelError
+	default:
+		return s
+	}
+}
+
+// RegisterLevel adds a level named name, such as "AUDIT", for use with Logf.
+// Its messages are filtered like those of a built-in level of the given
+// severity, so RegisterLevel("AUDIT", int(LevelWarn)) is output whenever
+// warnings are. Severities above LevelError are always output by enabled
+// groups. The level writes to the outputs, and with the color and syslog
+// priority, of the closest built-in level. Levels are shared by all Loggers,
+// and like RegisterGroup it is to be called in an init() function. It panics
+// with ErrLevelExists if the name is already used.
+func RegisterLevel(name string, severity int) Level {
+	customLevelsMu.Lock()
+	defer customLevelsMu.Unlock()
+
+	for _, levelName := range levelNames {
+		if levelName == name {
+			panic(ErrLevelExists)
+		}
+	}
+	for _, c := range customLevels {
+		if c.name == name {
+			panic(ErrLevelExists)
+		}
+	}
+
+	customLevels = append(customLevels, customLevel{name: name, severity: Level(severity)})
+	return LevelError + Level(len(customLevels))
+}
+
+// customMsg is a log message of a level added with RegisterLevel
+type customMsg struct {
+	msgData
+	lvl Level
+}
+
+func (m *customMsg) do(l *Logger) {
+	severity := m.lvl.severity()
+	if g := l.getGroup(m.group); l.allows(severity) && g != nil && g.allows(severity) {
+		l.printLog(m.lvl, &m.msgData)
+	}
+}
+
+// Log logs a message to the group at the given level, which can be a built-in
+// level or one added with RegisterLevel, for levels chosen at runtime such as
+// from an HTTP status code. Similar to fmt.Print(...)
+func (l *Logger) Log(group int, lvl Level, a ...interface{}) {
+	l.log(group, lvl, "", a...)
+}
+
+// Logf logs a message to the group at the given level, which can be a
+// built-in level or one added with RegisterLevel. Similar to fmt.Printf(...)
+func (l *Logger) Logf(group int, lvl Level, format string, a ...interface{}) {
+	l.log(group, lvl, format, a...)
+}
+
+// Log logs a message to the group at the given level, which can be a built-in
+// level or one added with RegisterLevel, for levels chosen at runtime such as
+// from an HTTP status code. Similar to fmt.Print(...)
+func Log(group int, lvl Level, a ...interface{}) {
+	std.log(group, lvl, "", a...)
+}
+
+// Logf logs a message to the group at the given level, which can be a
+// built-in level or one added with RegisterLevel. Similar to fmt.Printf(...)
+func Logf(group int, lvl Level, format string, a ...interface{}) {
+	std.log(group, lvl, format, a...)
+}
@@ -0,0 +1,1586 @@
+package trace
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Logger is an independent logging configuration with its own groups,
+// settings, and log goroutine, so that a library or a test can log without
+// affecting the rest of the program. The package level functions use a
+// default Logger writing to os.Stdout. A Logger must be created with New.
+type Logger struct {
+	// Channel for ordering and concurrently outputing log messages
+	logstream chan logApi
+
+	// Tracks when logRoutine has completed all requests
+	waitGroup sync.WaitGroup
+
+	// Closed when logRoutine has completed all requests, for waiting with a timeout
+	stopped chan struct{}
+
+	// Indicates whether logstream has been closed by Done
+	streamClosed atomic.Bool
+
+	// Indicates whether logRoutine has stopped reading logstream, set by Pause
+	paused atomic.Bool
+
+	// Requests received by logRoutine as Pause was called, run by Resume.
+	// Guarded by mu
+	held []logApi
+
+	// Wakes logRoutine when Resume is called
+	wake chan struct{}
+
+	// Serializes requests run by logRoutine with those run by synchronous callers,
+	// and guards groups against callers reading them
+	mu sync.Mutex
+
+	// Indicates whether requests run on the calling goroutine instead of logRoutine
+	synchronous atomic.Bool
+
+	// Master switch turning all log messages off. Read by the calling goroutines
+	disabled atomic.Bool
+
+	// Number of logging requests and commands the channel buffer holds
+	bufferSize int
+
+	// Indicates whether a message has been logged since the stream was created
+	streamUsed atomic.Bool
+
+	// Keeps all logging groups. Default group has index = 0 and name = ""
+	groups []*groupData
+
+	// Messages below this level are not output, whatever their group. It is
+	// LevelTrace while trace is enabled. It is only changed on logRoutine, but
+	// is also read by the calling goroutines
+	minLevel atomic.Int32
+
+	// How the caller of each log call is captured, as a CallerMode. It is read
+	// by the calling goroutines, so it is not changed through logstream
+	callerMode atomic.Int32
+
+	// Extra stack frames skipped when capturing the caller, as set with
+	// SetCallerSkip. It is read by the calling goroutines
+	extraSkip atomic.Int32
+
+	// Indicates whether to number log messages. It is read by the calling
+	// goroutines, so it is not changed through logstream
+	sequenceEnabled atomic.Bool
+
+	// Number of the last numbered log message
+	sequence atomic.Uint64
+
+	// What to do with log messages when logstream is full. Read by the calling goroutines
+	overflowPolicy atomic.Int32
+
+	// Number of log messages discarded by PolicyDrop
+	droppedCount atomic.Uint64
+
+	// Number of log messages discarded by PolicyDrop per group, as *atomic.Uint64 by group ID
+	groupDropped sync.Map
+
+	// Whether each group is enabled and its minimum level, as groupFilter by
+	// group ID. It is kept in step with groups on logRoutine and read by the
+	// calling goroutines without mu, which is held while lines are written
+	groupFilters sync.Map
+
+	// Key of the request ID in contexts passed to the Ctx functions. Read by the calling goroutines
+	contextIDKey atomic.Value
+
+	// Function giving the time of log messages, set with SetClock. Read by the calling goroutines
+	clock atomic.Value
+
+	// Layout of every log line
+	outputFormat Format
+
+	// Layout of the timestamp in FormatText
+	timeLayout string
+
+	// Location timestamps are written in
+	timeLocation *time.Location
+
+	// Whether lines start with a timestamp
+	timestamps bool
+
+	// Separates the parts of FormatText lines. Empty for a single space
+	fieldSeparator string
+
+	// Whether lines include the process ID
+	pidEnabled bool
+
+	// Whether lines include the hostname
+	hostnameEnabled bool
+
+	// How timestamps are written in FormatText
+	timeMode TimeMode
+
+	// Whether newlines, carriage returns, and tabs in FormatText messages are escaped
+	escapeControl bool
+
+	// Whether warn and error messages of the default group go to os.Stderr
+	splitStd bool
+
+	// When level names are colored in FormatText
+	colorMode ColorMode
+
+	// Renders lines instead of outputFormat when set
+	formatter func(e Entry) []byte
+
+	// Called for every message that is output when set
+	metricsHook func(group int, level Level)
+
+	// Called for every registered group when set
+	groupCreatedHook func(info GroupInfo)
+
+	// Receives every line of every group, in addition to their outputs, when set
+	mirror io.Writer
+
+	// Attached to every message when set
+	globalFields Fields
+
+	// Rewrites every formatted message when set
+	redactor func(msg string) string
+
+	// Whether the errors wrapped by errors logged with ErrorErr are written
+	errorChain bool
+
+	// Messages longer than this many bytes are truncated. Zero for no limit
+	maxMsgLength int
+
+	// Longest time batched lines are held back. Zero for flushInterval
+	flushEvery time.Duration
+
+	// Ticker of logRoutine writing batched lines. Nil while logRoutine is not running
+	flushTicker *time.Ticker
+
+	// Called on the log goroutine when writing to an output fails
+	errorHandler func(group int, err error)
+}
+
+type groupData struct {
+	name    string
+	outputs []io.Writer
+	enabled bool
+
+	// Output for error level messages. When nil, output is used instead
+	errOutput io.Writer
+
+	// Messages below this level are not output
+	minLevel Level
+
+	// Written after the timestamp of every line. Empty for none
+	prefix string
+
+	// Limits the messages output per second. Nil for no limit
+	limit *rateLimit
+
+	// Collapses repeated messages. Nil when off
+	dedup *dedupState
+
+	// Only every sampleRate-th trace message is output. Counted by sampleCount
+	sampleRate  int
+	sampleCount int
+
+	// Outputs replacing outputs and errOutput for a level, indexed by Level. Nil when not set
+	levelOutputs [LevelError + 1]io.Writer
+
+	// Whether outputs are wrapped in a batchWriter
+	batched bool
+
+	// Whether outputs are synced after every line
+	syncWrites bool
+
+	// Keeps the last lines output. Nil when off
+	ring *ringBuffer
+
+	// Counts of the group's messages for GroupStats, except Dropped
+	stats Stats
+
+	// Times a failed write is retried, waiting retryBackoff before the first retry
+	retryAttempts int
+	retryBackoff  time.Duration
+}
+
+// allows reports whether the group outputs messages of the given level
+func (g *groupData) allows(lvl Level) bool {
+	return g.enabled && lvl >= g.minLevel
+}
+
+// groupFilter is a copy of the fields of a group deciding whether it outputs a
+// message, for the calling goroutines
+type groupFilter struct {
+	enabled  bool
+	minLevel Level
+}
+
+// storeFilter is a helper function for updating the groupFilter of the group
+// after it changed, or removing it once the group is gone. The caller must hold mu.
+func (l *Logger) storeFilter(group int) {
+	if g := l.getGroup(group); g != nil {
+		l.groupFilters.Store(group, groupFilter{g.enabled, g.minLevel})
+	} else {
+		l.groupFilters.Delete(group)
+	}
+}
+
+// outputsFor is a helper function for selecting the outputs of a message of the given level
+func (g *groupData) outputsFor(lvl Level) []io.Writer {
+	lvl = lvl.base()
+	if w := g.levelOutputs[lvl]; w != nil {
+		return []io.Writer{w}
+	}
+	if lvl == LevelError && g.errOutput != nil {
+		return []io.Writer{g.errOutput}
+	}
+	return g.outputs
+}
+
+// allOutputs is a helper function for listing every output of the group, which may repeat
+func (g *groupData) allOutputs() []io.Writer {
+	all := append([]io.Writer{g.errOutput}, g.outputs...)
+	for _, w := range g.levelOutputs {
+		all = append(all, w)
+	}
+	return all
+}
+
+type logApi interface {
+	do(l *Logger)
+}
+
+// msgData is the content shared by all log messages
+type msgData struct {
+	group  int
+	t      time.Time
+	fields Fields
+
+	// Format string and arguments of the message. They are formatted into msg
+	// on the log goroutine only once the message is known to be output
+	format string
+	args   []interface{}
+	msg    string
+
+	// Source file and line, or function, of the call as set with SetCallerMode
+	caller string
+
+	// Whether caller is already resolved, or unknown, so send must not capture it
+	callerSet bool
+
+	// Request or correlation ID, if any
+	id string
+
+	// Order the message was logged in, when EnableSequence is on. Zero otherwise
+	seq uint64
+
+	// Error logged with ErrorErr, for SetErrorChain
+	err error
+
+	// Whether the message is output whatever the level and group settings, for ForceInfo
+	forced bool
+
+	// Groups the message is output to instead of group, for InfoGroups. Nil otherwise
+	groups []int
+}
+
+type traceMsg struct {
+	msgData
+}
+
+func (m *traceMsg) do(l *Logger) {
+	if g := l.getGroup(m.group); l.allows(LevelTrace) && g != nil && g.allows(LevelTrace) && g.sampled() {
+		l.printLog(LevelTrace, &m.msgData)
+	}
+	releaseMsg(LevelTrace, m, &m.msgData)
+}
+
+type infoMsg struct {
+	msgData
+}
+
+func (m *infoMsg) do(l *Logger) {
+	if m.groups != nil {
+		l.printGroups(LevelInfo, &m.msgData)
+	} else if g := l.getGroup(m.group); g != nil && (m.forced || l.allows(LevelInfo) && g.allows(LevelInfo)) {
+		l.printLog(LevelInfo, &m.msgData)
+	}
+	releaseMsg(LevelInfo, m, &m.msgData)
+}
+
+type warnMsg struct {
+	msgData
+}
+
+func (m *warnMsg) do(l *Logger) {
+	if g := l.getGroup(m.group); l.allows(LevelWarn) && g != nil && g.allows(LevelWarn) {
+		l.printLog(LevelWarn, &m.msgData)
+	}
+	releaseMsg(LevelWarn, m, &m.msgData)
+}
+
+type errorMsg struct {
+	msgData
+}
+
+func (m *errorMsg) do(l *Logger) {
+	if g := l.getGroup(m.group); l.allows(LevelError) && g != nil && g.allows(LevelError) {
+		l.printLog(LevelError, &m.msgData)
+	}
+	releaseMsg(LevelError, m, &m.msgData)
+}
+
+type cmdEnabletrace struct {
+	on bool
+}
+
+func (c *cmdEnabletrace) do(l *Logger) {
+	min := Level(l.minLevel.Load())
+	if c.on && min > LevelTrace {
+		l.minLevel.Store(int32(LevelTrace))
+	} else if !c.on && min < LevelInfo {
+		l.minLevel.Store(int32(LevelInfo))
+	}
+}
+
+type cmdSetMinLevel struct {
+	min Level
+}
+
+func (c *cmdSetMinLevel) do(l *Logger) {
+	l.minLevel.Store(int32(c.min))
+}
+
+// allows is a helper function reporting whether messages of the given level pass SetMinLevel
+func (l *Logger) allows(lvl Level) bool {
+	return lvl >= Level(l.minLevel.Load())
+}
+
+// enabled is a helper function reporting, on a calling goroutine, whether a
+// message of the given level to the group would currently be output
+func (l *Logger) enabled(group int, lvl Level) bool {
+	if !l.allows(lvl) {
+		return false
+	}
+
+	f, ok := l.groupFilters.Load(group)
+	return ok && f.(groupFilter).enabled && lvl >= f.(groupFilter).minLevel
+}
+
+type cmdEnableGroup struct {
+	group int
+	on    bool
+}
+
+func (c *cmdEnableGroup) do(l *Logger) {
+	if g := l.getGroup(c.group); g != nil {
+		g.enabled = c.on
+		l.storeFilter(c.group)
+	}
+}
+
+type cmdEnableGroupByPrefix struct {
+	prefix string
+	on     bool
+}
+
+func (c *cmdEnableGroupByPrefix) do(l *Logger) {
+	for id, g := range l.groups {
+		if g != nil && strings.HasPrefix(g.name, c.prefix) {
+			g.enabled = c.on
+			l.storeFilter(id)
+		}
+	}
+}
+
+type cmdFlush struct {
+	done chan struct{}
+}
+
+func (c *cmdFlush) do(l *Logger) {
+	l.writeSummaries()
+	l.flushOutputs(false)
+	close(c.done)
+}
+
+type cmdSetGroupLevel struct {
+	group int
+	min   Level
+}
+
+func (c *cmdSetGroupLevel) do(l *Logger) {
+	if g := l.getGroup(c.group); g != nil {
+		g.minLevel = c.min
+		l.storeFilter(c.group)
+	}
+}
+
+type cmdSetGroupPrefix struct {
+	group  int
+	prefix string
+}
+
+func (c *cmdSetGroupPrefix) do(l *Logger) {
+	if g := l.getGroup(c.group); g != nil {
+		g.prefix = c.prefix
+	}
+}
+
+type cmdSetErrorOutput struct {
+	group  int
+	output io.Writer
+}
+
+func (c *cmdSetErrorOutput) do(l *Logger) {
+	if g := l.getGroup(c.group); g != nil {
+		g.errOutput = c.output
+	}
+}
+
+type cmdSetLevelOutput struct {
+	group  int
+	lvl    Level
+	output io.Writer
+}
+
+func (c *cmdSetLevelOutput) do(l *Logger) {
+	if g := l.getGroup(c.group); g != nil && c.lvl >= LevelTrace && c.lvl <= LevelError {
+		g.levelOutputs[c.lvl] = c.output
+	}
+}
+
+type cmdUnregisterGroup struct {
+	group int
+}
+
+func (c *cmdUnregisterGroup) do(l *Logger) {
+	g := l.getGroup(c.group)
+	if g == nil || c.group == DefaultGroupId {
+		return
+	}
+
+	l.groups[c.group] = nil
+	l.storeFilter(c.group)
+	closeGroupOutputs([]*groupData{g}, nil)
+}
+
+type cmdAddGroupOutput struct {
+	group  int
+	output io.Writer
+}
+
+func (c *cmdAddGroupOutput) do(l *Logger) {
+	if g := l.getGroup(c.group); g != nil {
+		if g.batched {
+			g.outputs = append(g.outputs, batched(c.output))
+		} else {
+			g.outputs = append(g.outputs, c.output)
+		}
+	}
+}
+
+type cmdRegisterGroup struct {
+	name   string
+	output io.Writer
+	on     bool
+
+	// Results for the caller, valid once done is closed
+	group int
+	err   error
+	hook  func(info GroupInfo)
+	done  chan struct{}
+}
+
+func (c *cmdRegisterGroup) do(l *Logger) {
+	defer close(c.done)
+
+	if id, ok := l.findGroup(c.name); ok {
+		c.group, c.err = id, ErrGroupExists
+		return
+	}
+
+	l.groups = append(l.groups, &groupData{name: c.name, outputs: []io.Writer{c.output}, enabled: c.on})
+	c.group = len(l.groups) - 1
+	l.storeFilter(c.group)
+	c.hook = l.groupCreatedHook
+}
+
+type cmdClearGroups struct {
+	done chan struct{}
+}
+
+func (c *cmdClearGroups) do(l *Logger) {
+	l.clearGroups()
+	close(c.done)
+}
+
+type cmdSetOutput struct {
+	group  int
+	output io.Writer
+}
+
+func (c *cmdSetOutput) do(l *Logger) {
+	g := l.getGroup(c.group)
+	if g == nil {
+		return
+	}
+
+	for _, output := range g.outputs {
+		if b, ok := output.(*batchWriter); ok {
+			if err := b.flush(false); err != nil {
+				l.errorHandler(c.group, err)
+			}
+		}
+	}
+
+	if g.batched {
+		g.outputs = []io.Writer{batched(c.output)}
+	} else {
+		g.outputs = []io.Writer{c.output}
+	}
+}
+
+type cmdSetErrorHandler struct {
+	handler func(group int, err error)
+}
+
+func (c *cmdSetErrorHandler) do(l *Logger) {
+	l.errorHandler = c.handler
+}
+
+// getGroup is a helper function for looking up a group. It returns nil if the
+// group was never registered or has been unregistered.
+func (l *Logger) getGroup(group int) *groupData {
+	if group < 0 || group >= len(l.groups) {
+		return nil
+	}
+	return l.groups[group]
+}
+
+// findGroup is a helper function for looking up a group by name. The caller must hold mu.
+func (l *Logger) findGroup(name string) (int, bool) {
+	for id, group := range l.groups {
+		if group != nil && name == group.name {
+			return id, true
+		}
+	}
+
+	return 0, false
+}
+
+// closeGroupOutputs is a helper function for closing every output of the groups
+// once, except those in keep. Only closers are tracked, since outputs such as a
+// WriterFunc cannot be map keys.
+func closeGroupOutputs(groups []*groupData, keep map[io.Closer]bool) {
+	closed := make(map[io.Closer]bool)
+	for output := range keep {
+		closed[output] = true
+	}
+
+	for _, g := range groups {
+		if g == nil {
+			continue
+		}
+		for _, output := range g.allOutputs() {
+			if c, ok := output.(io.Closer); ok && !closed[c] {
+				closed[c] = true
+				closeOutput(output)
+			}
+		}
+	}
+}
+
+// clearGroups is a helper function for removing all groups but the default
+// group and closing their outputs. The caller must hold mu.
+func (l *Logger) clearGroups() {
+	keep := make(map[io.Closer]bool)
+	for _, output := range l.groups[DefaultGroupId].allOutputs() {
+		if c, ok := output.(io.Closer); ok {
+			keep[c] = true
+		}
+	}
+
+	closeGroupOutputs(l.groups[DefaultGroupId+1:], keep)
+	l.groups = l.groups[:DefaultGroupId+1]
+	l.groupFilters.Range(func(group, _ interface{}) bool {
+		if group.(int) != DefaultGroupId {
+			l.groupFilters.Delete(group)
+		}
+		return true
+	})
+
+	// IDs are reused, so the drop counts of the removed groups must not carry over
+	l.groupDropped.Range(func(group, _ interface{}) bool {
+		if group.(int) != DefaultGroupId {
+			l.groupDropped.Delete(group)
+		}
+		return true
+	})
+}
+
+// closeOutput is a helper function for closing a writer that implements io.Closer.
+// The standard streams are never closed.
+func closeOutput(output io.Writer) {
+	if output == os.Stdout || output == os.Stderr {
+		return
+	}
+	if c, ok := output.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// defaultErrorHandler reports failed writes on os.Stderr
+func defaultErrorHandler(group int, err error) {
+	fmt.Fprintf(os.Stderr, "trace: write to group %d failed: %v\n", group, err)
+}
+
+// defaultGroup is a helper function for creating the default logging group
+func defaultGroup(output io.Writer, enabled bool) *groupData {
+	return &groupData{name: "", outputs: []io.Writer{output}, enabled: enabled, errOutput: os.Stderr}
+}
+
+// log is a helper function for processing new log requests from the caller
+func (l *Logger) log(group int, lvl Level, format string, a ...interface{}) {
+	l.send(lvl, msgData{group: group}, format, a...)
+}
+
+// logFields is a helper function for processing new log requests that carry fields
+func (l *Logger) logFields(group int, lvl Level, fields Fields, format string, a ...interface{}) {
+	l.send(lvl, msgData{group: group, fields: fields}, format, a...)
+}
+
+// send is a helper function for completing a log message and queueing it.
+// It must be called exactly callerSkip frames below the caller's log call.
+func (l *Logger) send(lvl Level, data msgData, format string, a ...interface{}) {
+	if l.disabled.Load() || l.streamClosed.Load() && !l.synchronous.Load() {
+		return
+	}
+
+	if data.t.IsZero() {
+		data.t = l.now()
+	}
+
+	if l.sequenceEnabled.Load() {
+		data.seq = l.sequence.Add(1)
+	}
+
+	if !data.callerSet {
+		if mode := CallerMode(l.callerMode.Load()); mode != CallerNone {
+			if pc, file, line, ok := runtime.Caller(callerSkip + int(l.extraSkip.Load())); ok {
+				data.caller = mode.describe(pc, file, line)
+			}
+		}
+	}
+
+	data.format = format
+	data.args = a
+
+	cmd := newMsg(lvl, data)
+
+	if !l.streamUsed.Load() {
+		l.streamUsed.Store(true)
+	}
+
+	if l.synchronous.Load() {
+		l.enqueue(cmd)
+		return
+	}
+	l.queueMsg(cmd, data.group, data.groups)
+}
+
+// queueMsg is a helper function for passing a log message of the group, or of
+// the groups if it has several, to the log goroutine. Messages logged while Done
+// closes the stream are dropped instead of panicking.
+func (l *Logger) queueMsg(cmd logApi, group int, groups []int) {
+	defer func() {
+		if r := recover(); r != nil && !l.streamClosed.Load() {
+			panic(r)
+		}
+	}()
+
+	if OverflowPolicy(l.overflowPolicy.Load()) == PolicyDrop {
+		select {
+		case l.logstream <- cmd:
+		default:
+			l.droppedCount.Add(1)
+			if groups == nil {
+				l.countDropped(group)
+			}
+			for _, group := range groups {
+				l.countDropped(group)
+			}
+		}
+		return
+	}
+
+	l.logstream <- cmd
+}
+
+// enqueue is a helper function for passing a request to the log goroutine, or
+// for running it on the calling goroutine in synchronous mode and once Done has
+// closed the stream
+func (l *Logger) enqueue(cmd logApi) {
+	if !l.synchronous.Load() && !l.streamClosed.Load() && l.sendCmd(cmd) {
+		return
+	}
+
+	l.mu.Lock()
+	cmd.do(l)
+	l.mu.Unlock()
+}
+
+// sendCmd is a helper function for passing a request to the log goroutine. It
+// returns false instead of panicking if Done closes the stream meanwhile.
+func (l *Logger) sendCmd(cmd logApi) (sent bool) {
+	defer func() {
+		if r := recover(); r != nil && !l.streamClosed.Load() {
+			panic(r)
+		}
+	}()
+
+	l.logstream <- cmd
+	return true
+}
+
+// logRoutine is a goroutine for outputing logging in parallel
+func (l *Logger) logRoutine() {
+	l.mu.Lock()
+	ticker := time.NewTicker(l.flushPeriod())
+	l.flushTicker = ticker
+	l.mu.Unlock()
+
+	for {
+		stream := l.logstream
+		if l.paused.Load() {
+			stream = nil
+		}
+
+		select {
+		case i, ok := <-stream:
+			if !ok {
+				l.run(&cmdStop{ticker})
+				close(l.stopped)
+				l.waitGroup.Done()
+				return
+			}
+			l.run(i)
+		case <-ticker.C:
+			if !l.paused.Load() {
+				l.run(&cmdTick{})
+			}
+		case <-l.wake:
+		}
+	}
+}
+
+// run is a helper function for running a request on logRoutine. Requests
+// received as Pause is called are held for Resume.
+func (l *Logger) run(i logApi) {
+	l.mu.Lock()
+	if l.paused.Load() {
+		l.held = append(l.held, i)
+	} else {
+		i.do(l)
+	}
+	l.mu.Unlock()
+}
+
+// cmdTick is run by logRoutine at every tick of its ticker, for the features
+// that act on time rather than on requests
+type cmdTick struct{}
+
+func (c *cmdTick) do(l *Logger) {
+	l.writeSummaries()
+	l.flushOutputs(false)
+}
+
+// cmdStop is run by logRoutine once Done has closed the stream and every
+// queued request has run. It writes out the state held back by the features
+// that act on time.
+type cmdStop struct {
+	ticker *time.Ticker
+}
+
+func (c *cmdStop) do(l *Logger) {
+	c.ticker.Stop()
+	l.flushTicker = nil
+	l.writeSummaries()
+	l.flushOutputs(true)
+}
+
+// formatMsg is a helper function for formatting the message from its format
+// string and arguments, passing it through the redactor, and truncating it to
+// the maximum length. Later calls have no effect.
+func (l *Logger) formatMsg(m *msgData) {
+	if m.args == nil && len(m.format) == 0 {
+		return
+	}
+
+	if len(m.format) > 0 {
+		m.msg = fmt.Sprintf(m.format, m.args...)
+	} else {
+		m.msg = fmt.Sprint(m.args...)
+	}
+	if l.redactor != nil {
+		m.msg = l.redactor(m.msg)
+	}
+	if l.maxMsgLength > 0 {
+		m.msg = truncate(m.msg, l.maxMsgLength)
+	}
+	m.format = ""
+	m.args = nil
+}
+
+// printLog is a helper function for formating a log message and writing it
+// to each of the group's outputs, unless the group's deduplication or rate
+// limit drops it.
+// A failing output does not stop the others.
+func (l *Logger) printLog(lvl Level, m *msgData) {
+	g := l.groups[m.group]
+	l.addGlobalFields(m)
+	if g.dedup != nil {
+		l.formatMsg(m)
+		if g.dedup.repeated(lvl, m) {
+			g.stats.Deduped++
+			return
+		}
+		l.writeRepeated(m.group, m.t)
+		g.dedup.last(lvl, m)
+	}
+	if g.limit != nil {
+		if !g.limit.allow(m.t) {
+			g.stats.RateLimited++
+			return
+		}
+		l.writeSuppressed(m.group, m.t)
+	}
+
+	g.stats.Emitted++
+	if l.metricsHook != nil {
+		l.metricsHook(m.group, lvl)
+	}
+	if g.ring == nil && l.mirror == nil && discards(l.outputsFor(m.group, lvl)) {
+		return
+	}
+
+	l.formatMsg(m)
+	l.writeMsg(lvl, m)
+}
+
+// writeSummaries is a helper function for writing the pending summary lines of
+// every group, the repeated and suppressed message counts
+func (l *Logger) writeSummaries() {
+	now := l.now()
+	for id, g := range l.groups {
+		if g != nil {
+			l.writeRepeated(id, now)
+			l.writeSuppressed(id, now)
+		}
+	}
+}
+
+// writeMsg is a helper function for writing a formatted message to each of the group's outputs
+func (l *Logger) writeMsg(lvl Level, m *msgData) {
+	g := l.groups[m.group]
+	outputs := l.outputsFor(m.group, lvl)
+	if l.mirror != nil {
+		outputs = append(outputs[:len(outputs):len(outputs)], l.mirror)
+	}
+
+	var line, colored []byte
+	for _, output := range outputs {
+		if l.useColor(output) {
+			if colored == nil {
+				colored = l.formatLine(lvl, m, true)
+			}
+			l.writeLine(m.group, lvl, output, colored)
+			continue
+		}
+		if line == nil {
+			line = l.formatLine(lvl, m, false)
+		}
+		l.writeLine(m.group, lvl, output, line)
+	}
+
+	if g.ring != nil {
+		switch {
+		case l.formatter == nil && l.outputFormat == FormatLengthPrefixed:
+			// The ring is read as text, so it keeps the lines without their frames
+			g.writeRing(l.formatText(lvl, m, false))
+		case line == nil:
+			line = l.formatLine(lvl, m, false)
+			fallthrough
+		default:
+			g.writeRing(line)
+		}
+	}
+}
+
+// formatLine is a helper function for rendering a log message in the current format
+func (l *Logger) formatLine(lvl Level, m *msgData, color bool) []byte {
+	if l.formatter != nil {
+		return l.formatter(l.entry(lvl, m))
+	}
+
+	switch l.outputFormat {
+	case FormatJSON:
+		return l.formatJSON(lvl, m)
+	case FormatLogfmt:
+		return l.formatLogfmt(lvl, m)
+	case FormatLengthPrefixed:
+		return frame(l.formatText(lvl, m, color))
+	default:
+		return l.formatText(lvl, m, color)
+	}
+}
+
+// levelWriter is implemented by outputs that need the level of each line,
+// such as syslog which has a priority per message
+type levelWriter interface {
+	writeLevel(lvl Level, p []byte) (n int, err error)
+}
+
+// writeLine is a helper function for writing a formatted line and reporting failures
+func (l *Logger) writeLine(group int, lvl Level, output io.Writer, line []byte) {
+	g := l.getGroup(group)
+	err := writeRetried(g, lvl, output, line)
+
+	if err == nil {
+		if s, ok := output.(syncer); ok && g != nil && g.syncWrites {
+			err = syncOutput(s)
+		}
+	}
+
+	if err != nil {
+		l.errorHandler(group, err)
+	}
+}
+
+// reset is a helper function for starting the log goroutine. Sample counts start
+// over. It does nothing while the log goroutine is still running, as two of them
+// would both read logstream and both close stopped; call Done first.
+func (l *Logger) reset() {
+	if l.stopped != nil {
+		select {
+		case <-l.stopped:
+		default:
+			return
+		}
+	}
+
+	for _, g := range l.groups {
+		if g != nil {
+			g.sampleCount = 0
+		}
+	}
+
+	l.logstream = make(chan logApi, l.bufferSize)
+	l.stopped = make(chan struct{})
+	l.streamClosed.Store(false)
+	l.streamUsed.Store(false)
+	l.waitGroup.Add(1)
+	go l.logRoutine()
+}
+
+// New creates a Logger whose default group writes to output. Like the package
+// level configuration, trace level logging is off and error level messages of
+// the default group are written to os.Stderr. Call Done when finished with it.
+func New(output io.Writer) *Logger {
+	l := &Logger{
+		groups:       []*groupData{defaultGroup(output, true)},
+		bufferSize:   chanBufSize,
+		outputFormat: FormatText,
+		timeLayout:   DefaultTimeFormat,
+		timeLocation: time.UTC,
+		timestamps:   true,
+		errorHandler: defaultErrorHandler,
+		wake:         make(chan struct{}, 1),
+	}
+	l.minLevel.Store(int32(LevelInfo))
+	l.storeFilter(DefaultGroupId)
+	l.reset()
+	return l
+}
+
+// AddGroupOutput adds another output location to the group so that its logs
+// are written to every output in turn, for example to both a file and os.Stdout.
+// If one output fails the others are still written. Error level messages keep
+// going to the error output if one is set (see SetErrorOutput).
+func (l *Logger) AddGroupOutput(group int, output io.Writer) {
+	l.enqueue(&cmdAddGroupOutput{group, output})
+}
+
+// ClearGroups removes all groups but the default group, returning the Logger
+// to its initial set of groups without restarting it, for example between
+// tests. Messages queued for the removed groups are output first, and their
+// outputs are then closed unless the default group also writes to them. Group
+// IDs are reused afterwards, so IDs of removed groups must not be used again.
+func (l *Logger) ClearGroups() {
+	c := &cmdClearGroups{done: make(chan struct{})}
+	l.enqueue(c)
+	<-c.done
+}
+
+// Close is called at end of program instead of Done to output all queued logs
+// and then close every group output that implements io.Closer, such as files
+// opened by RegisterFileGroup. os.Stdout and os.Stderr are never closed. The
+// Logger must not be used afterwards.
+func (l *Logger) Close() {
+	l.Done()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	closeGroupOutputs(l.groups, nil)
+}
+
+// Done is called at end of program to ensure all logs are printed. Calling it
+// again has no effect until the Logger is restarted with Reset. Messages logged
+// afterwards, for example by background goroutines during shutdown, are
+// dropped, while other calls such as EnableTrace or RegisterGroup take effect
+// on the calling goroutine. Output halted by Pause is resumed.
+func (l *Logger) Done() {
+	if !l.streamClosed.Swap(true) {
+		close(l.logstream)
+	}
+	l.Resume()
+	l.waitGroup.Wait()
+}
+
+// DoneTimeout is called at end of program like Done, but waits at most d for
+// queued logs to be output, so that a hanging writer such as a stuck network
+// connection cannot keep the program from exiting. It returns ErrDoneTimeout if
+// the logs were not all output in time; the log goroutine then keeps writing
+// in the background.
+func (l *Logger) DoneTimeout(d time.Duration) error {
+	if !l.streamClosed.Swap(true) {
+		close(l.logstream)
+	}
+	l.Resume()
+
+	select {
+	case <-l.stopped:
+		return nil
+	case <-time.After(d):
+		return ErrDoneTimeout
+	}
+}
+
+// DroppedCount returns the number of log messages discarded because the buffer
+// was full while PolicyDrop was in effect.
+func (l *Logger) DroppedCount() uint64 {
+	return l.droppedCount.Load()
+}
+
+// EnableCaller turns on or off capturing the source file and line of every log
+// call, such as "server.go:42". It is off by default because capturing the
+// caller has a measurable cost on the calling goroutine. The change applies to
+// log calls made after EnableCaller returns. It is equivalent to SetCallerMode
+// with CallerFile or CallerNone.
+func (l *Logger) EnableCaller(on bool) {
+	if on {
+		l.SetCallerMode(CallerFile)
+	} else {
+		l.SetCallerMode(CallerNone)
+	}
+}
+
+// EnableDefault turns the default group logging on or off. It is equivalent
+// to EnableGroup(DefaultGroupId, on), and can be called at startup, before
+// anything is logged, to silence the default group.
+func (l *Logger) EnableDefault(on bool) {
+	l.EnableGroup(DefaultGroupId, on)
+}
+
+// EnableGroup turns the group logging on or off
+func (l *Logger) EnableGroup(group int, on bool) {
+	l.enqueue(&cmdEnableGroup{group, on})
+}
+
+// EnableGroupByPrefix turns on or off every group whose name starts with
+// prefix, so that dotted names form a hierarchy: EnableGroupByPrefix("db", false)
+// turns off "db", "db.query" and "db.tx". Pass "db." to leave "db" itself
+// alone. Groups registered later are not affected.
+func (l *Logger) EnableGroupByPrefix(prefix string, on bool) {
+	l.enqueue(&cmdEnableGroupByPrefix{prefix, on})
+}
+
+// EnableSequence turns on or off numbering every log message, written like
+// #000123, in the order the logging calls were made. Output of concurrent
+// goroutines can then be put back in call order. Messages dropped by
+// PolicyDrop still take a number, so gaps show where they were. It is off
+// by default. The change applies to log calls made after EnableSequence returns.
+func (l *Logger) EnableSequence(on bool) {
+	l.sequenceEnabled.Store(on)
+}
+
+// EnableTrace turns tracing level logging on or off. It is kept for
+// compatibility with SetMinLevel: turning trace on lowers the minimum level to
+// LevelTrace, and turning it off raises it to LevelInfo if it was lower.
+func (l *Logger) EnableTrace(on bool) {
+	l.enqueue(&cmdEnabletrace{on})
+}
+
+// Error logs a message to default group at error level. Similar to fmt.Print(...)
+func (l *Logger) Error(a ...interface{}) {
+	l.log(0, LevelError, "", a...)
+}
+
+// Errorf logs a message to default group at error level. Similar to fmt.Printf(...)
+func (l *Logger) Errorf(format string, a ...interface{}) {
+	l.log(0, LevelError, format, a...)
+}
+
+// Errorg logs a message to given group at error level. Similar to fmt.Print(...)
+func (l *Logger) Errorg(group int, a ...interface{}) {
+	l.log(group, LevelError, "", a...)
+}
+
+// Errorgf logs a message to given group at error level. Similar to fmt.Printf(...)
+func (l *Logger) Errorgf(group int, format string, a ...interface{}) {
+	l.log(group, LevelError, format, a...)
+}
+
+// Fatal logs a message to default group at error level, flushes all queued logs,
+// and exits the program with FatalExitCode. Similar to fmt.Print(...)
+//
+// Unlike the other logging functions, Fatal blocks until the output is written.
+func (l *Logger) Fatal(a ...interface{}) {
+	l.log(0, LevelError, "", a...)
+	l.Flush()
+	osExit(FatalExitCode)
+}
+
+// Fatalf logs a message to default group at error level, flushes all queued logs,
+// and exits the program with FatalExitCode. Similar to fmt.Printf(...)
+func (l *Logger) Fatalf(format string, a ...interface{}) {
+	l.log(0, LevelError, format, a...)
+	l.Flush()
+	osExit(FatalExitCode)
+}
+
+// Fatalg logs a message to given group at error level, flushes all queued logs,
+// and exits the program with FatalExitCode. Similar to fmt.Print(...)
+func (l *Logger) Fatalg(group int, a ...interface{}) {
+	l.log(group, LevelError, "", a...)
+	l.Flush()
+	osExit(FatalExitCode)
+}
+
+// Fatalgf logs a message to given group at error level, flushes all queued logs,
+// and exits the program with FatalExitCode. Similar to fmt.Printf(...)
+func (l *Logger) Fatalgf(group int, format string, a ...interface{}) {
+	l.log(group, LevelError, format, a...)
+	l.Flush()
+	osExit(FatalExitCode)
+}
+
+// Flush blocks until all logs queued before the call have been output. Unlike
+// Done, logging can continue afterwards. Call it before os.Exit or in crash
+// handlers to make sure pending logs are not lost.
+func (l *Logger) Flush() {
+	done := make(chan struct{})
+	l.enqueue(&cmdFlush{done})
+	<-done
+}
+
+// GroupByName returns the ID of the group registered with the given name and
+// whether it was found. It lets packages share a group registered elsewhere
+// without passing its ID around. The default group has the empty name.
+func (l *Logger) GroupByName(name string) (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.findGroup(name)
+}
+
+// GroupWriterOf returns the output the group writes to, as set by RegisterGroup,
+// SetGroupOutput, or SetDefaultGroup, so that it can be wrapped and set again.
+// Outputs added with AddGroupOutput are not returned. It returns nil if the group
+// is not registered. The package keeps writing to the output on the log
+// goroutine, so writing to it directly may interleave with log lines, and it
+// must not be closed while the group uses it.
+func (l *Logger) GroupWriterOf(group int) io.Writer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	g := l.getGroup(group)
+	if g == nil || len(g.outputs) == 0 {
+		return nil
+	}
+	return unbatched(g.outputs[0])
+}
+
+// Info logs a message to default group at info level. Similar to fmt.Print(...)
+func (l *Logger) Info(a ...interface{}) {
+	l.log(0, LevelInfo, "", a...)
+}
+
+// Infof logs a message to default group at info level. Similar to fmt.Printf(...)
+func (l *Logger) Infof(format string, a ...interface{}) {
+	l.log(0, LevelInfo, format, a...)
+}
+
+// Infog logs a message to given group at info level. Similar to fmt.Print(...)
+func (l *Logger) Infog(group int, a ...interface{}) {
+	l.log(group, LevelInfo, "", a...)
+}
+
+// Infogf logs a message to given group. Similar to fmt.Printf(...)
+func (l *Logger) Infogf(group int, format string, a ...interface{}) {
+	l.log(group, LevelInfo, format, a...)
+}
+
+// IsGroupEnabled reports whether the group is registered and turned on. Like
+// IsTraceEnabled it reflects EnableGroup calls already processed by the log goroutine.
+func (l *Logger) IsGroupEnabled(group int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	g := l.getGroup(group)
+	return g != nil && g.enabled
+}
+
+// IsTraceEnabled reports whether trace level logging is on. Use it to skip
+// building expensive trace messages:
+//
+//	if trace.IsTraceEnabled() {
+//		trace.Tracef("state: %s", dump())
+//	}
+//
+// EnableTrace is applied by the log goroutine in order with queued logs, so a
+// change only shows once it has been processed. Call Flush first to be certain.
+func (l *Logger) IsTraceEnabled() bool {
+	return l.allows(LevelTrace)
+}
+
+// ListGroups returns a snapshot of the registered logging groups, including
+// the default group, ordered by ID. It is safe to call concurrently with logging
+// and with RegisterGroup.
+func (l *Logger) ListGroups() []GroupInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	list := make([]GroupInfo, 0, len(l.groups))
+	for id, group := range l.groups {
+		if group != nil {
+			list = append(list, GroupInfo{ID: id, Name: group.name, Enabled: group.enabled})
+		}
+	}
+	return list
+}
+
+// Panic logs a message to default group at error level, flushes all queued logs,
+// and panics with the message. Similar to fmt.Print(...)
+//
+// The formatted message is both logged and passed to panic. Unlike the other
+// logging functions, Panic blocks until the output is written so the message
+// is not lost while the stack unwinds.
+func (l *Logger) Panic(a ...interface{}) {
+	msg := fmt.Sprint(a...)
+	l.log(0, LevelError, "", msg)
+	l.Flush()
+	panic(msg)
+}
+
+// Panicf logs a message to default group at error level, flushes all queued logs,
+// and panics with the message. Similar to fmt.Printf(...)
+func (l *Logger) Panicf(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	l.log(0, LevelError, "", msg)
+	l.Flush()
+	panic(msg)
+}
+
+// Panicg logs a message to given group at error level, flushes all queued logs,
+// and panics with the message. Similar to fmt.Print(...)
+func (l *Logger) Panicg(group int, a ...interface{}) {
+	msg := fmt.Sprint(a...)
+	l.log(group, LevelError, "", msg)
+	l.Flush()
+	panic(msg)
+}
+
+// Panicgf logs a message to given group at error level, flushes all queued logs,
+// and panics with the message. Similar to fmt.Printf(...)
+func (l *Logger) Panicgf(group int, format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	l.log(group, LevelError, "", msg)
+	l.Flush()
+	panic(msg)
+}
+
+// RegisterGroup registers a new logging group.
+//
+// It is to be called in a package's init() function. It returns a unique group ID
+// for the calling package to store so it can later change the group configuration.
+// It panics if the group name already exists. See RegisterGroupE.
+func (l *Logger) RegisterGroup(name string, output io.Writer, on bool) int {
+	group, err := l.RegisterGroupE(name, output, on)
+	if err != nil {
+		panic(err)
+	}
+
+	return group
+}
+
+// RegisterGroupE registers a new logging group like RegisterGroup, but returns
+// ErrGroupExists instead of panicking if the group name already exists. In that
+// case the returned ID is that of the existing group so the caller can reuse it.
+func (l *Logger) RegisterGroupE(name string, output io.Writer, on bool) (int, error) {
+	c := &cmdRegisterGroup{name: name, output: output, on: on, done: make(chan struct{})}
+	l.enqueue(c)
+	<-c.done
+
+	if c.hook != nil {
+		c.hook(GroupInfo{ID: c.group, Name: name, Enabled: on})
+	}
+	return c.group, c.err
+}
+
+// Reset restarts the Logger after Done, so that test suites calling Done in
+// every test can log again. If the Logger is still running it is first drained
+// like Done, so no goroutine is left behind. Settings and registered groups are
+// kept unless clearGroups is set, in which case only the default group remains
+// and the outputs of the removed groups are closed. Group IDs are then reused.
+func (l *Logger) Reset(clearGroups bool) {
+	l.Done()
+
+	if clearGroups {
+		l.mu.Lock()
+		l.clearGroups()
+		l.mu.Unlock()
+	}
+
+	l.reset()
+}
+
+// SetBufferSize sets the number of log messages and commands that can be queued
+// before logging blocks or drops messages (see SetOverflowPolicy). The default is 1024.
+//
+// The stream is recreated with the new size, so SetBufferSize must be called
+// before the first log and before other goroutines use the package, typically
+// at the start of main. Commands queued earlier, such as EnableTrace, are kept.
+// It returns ErrStreamActive if messages have already been logged; to change the
+// size after logging has started, call Done before restarting the stream.
+func (l *Logger) SetBufferSize(n int) error {
+	if n < 1 {
+		return ErrBufferSize
+	}
+	if l.streamUsed.Load() {
+		return ErrStreamActive
+	}
+
+	l.bufferSize = n
+	l.Done()
+	l.reset()
+	return nil
+}
+
+// SetDefaultGroup sets the output location of the default logging group,
+// replacing any outputs added with AddGroupOutput. Like SetGroupOutput the
+// change is applied between two lines.
+//
+// Error level messages of the default group are written to os.Stderr unless
+// changed with SetErrorOutput.
+func (l *Logger) SetDefaultGroup(output io.Writer) {
+	l.enqueue(&cmdSetOutput{DefaultGroupId, output})
+}
+
+// SetDefaultOutput is an alias of SetDefaultGroup kept for backward compatibility.
+//
+// Deprecated: Use SetDefaultGroup.
+func (l *Logger) SetDefaultOutput(output io.Writer) {
+	l.SetDefaultGroup(output)
+}
+
+// SetEnabled turns all logging on or off. While off, logging calls return
+// immediately without reading the clock, formatting, or queueing, so it is the
+// cheapest way to silence the package, for example in benchmarks. It is
+// independent of EnableGroup and EnableTrace. Logging is on by default.
+func (l *Logger) SetEnabled(on bool) {
+	l.disabled.Store(!on)
+}
+
+// SetErrorHandler sets the function called when writing a log line to one of a
+// group's outputs fails. By default failures are reported on os.Stderr. A nil
+// handler restores the default.
+//
+// The handler runs on the log goroutine, so it must not block and must not log
+// through this package, which would deadlock once the buffer is full.
+func (l *Logger) SetErrorHandler(handler func(group int, err error)) {
+	if handler == nil {
+		handler = defaultErrorHandler
+	}
+	l.enqueue(&cmdSetErrorHandler{handler})
+}
+
+// SetErrorOutput sets the output location for error level messages of the given
+// group. By default the default group writes errors to os.Stderr and all other
+// groups write errors to their regular output. Passing nil makes the group write
+// errors to its regular output.
+func (l *Logger) SetErrorOutput(group int, output io.Writer) {
+	l.enqueue(&cmdSetErrorOutput{group, output})
+}
+
+// SetGroupOutput sets the output location of the group, replacing any outputs
+// added with AddGroupOutput, for example to switch a group to a new file after
+// rotating it externally. Like all changes it is applied by the log goroutine
+// between two lines, so a line is never split between the old and the new
+// output, and the old output can be closed once Flush returns. The old output
+// is not closed. It panics with ErrUnknownGroup if the group was never
+// registered or has been unregistered, and if output is nil.
+func (l *Logger) SetGroupOutput(group int, output io.Writer) {
+	l.mu.Lock()
+	g := l.getGroup(group)
+	l.mu.Unlock()
+
+	if g == nil {
+		panic(ErrUnknownGroup)
+	}
+	if output == nil {
+		panic("trace: SetGroupOutput with nil output")
+	}
+
+	l.enqueue(&cmdSetOutput{group, output})
+}
+
+// SetGroupLevel sets the minimum level the group outputs. Messages below min
+// are dropped, for example SetGroupLevel(audit, LevelInfo) drops trace messages
+// of the audit group. Trace messages additionally require EnableTrace. Groups
+// output all levels by default.
+func (l *Logger) SetGroupLevel(group int, min Level) {
+	l.enqueue(&cmdSetGroupLevel{group, min})
+}
+
+// SetGroupPrefix sets a static prefix, such as a service or host name, written
+// after the timestamp of every line of the group. With FormatJSON and
+// FormatLogfmt it is written as the "prefix" key. An empty prefix removes it.
+func (l *Logger) SetGroupPrefix(group int, prefix string) {
+	l.enqueue(&cmdSetGroupPrefix{group, prefix})
+}
+
+// SetLevelOutput sets the output location for messages of the given level of
+// the group, so that one group can for example write trace messages to a debug
+// file and the other levels to its main output. It takes precedence over the
+// group's outputs and SetErrorOutput. Passing nil restores them for the level.
+func (l *Logger) SetLevelOutput(group int, level Level, output io.Writer) {
+	l.enqueue(&cmdSetLevelOutput{group, level, output})
+}
+
+// SetMinLevel sets the minimum level output by all groups, for example
+// SetMinLevel(LevelWarn) drops trace and info messages everywhere. Groups can
+// raise it further with SetGroupLevel. Setting it to LevelTrace turns trace on
+// like EnableTrace(true), and any higher level turns trace off. The default is
+// LevelInfo. Like EnableTrace it applies in order with queued logs.
+func (l *Logger) SetMinLevel(min Level) {
+	l.enqueue(&cmdSetMinLevel{min})
+}
+
+// SetOverflowPolicy sets what happens to a log message when the buffer is full.
+// By default the logging call blocks until there is room. With PolicyDrop the
+// message is discarded and counted instead so callers never stall. Commands such
+// as EnableTrace are never dropped.
+func (l *Logger) SetOverflowPolicy(policy OverflowPolicy) {
+	l.overflowPolicy.Store(int32(policy))
+}
+
+// SetSynchronous turns synchronous mode on or off. In synchronous mode log
+// messages and commands are output on the calling goroutine before the call
+// returns, instead of being queued, which makes tests deterministic without
+// calling Done. Turning it on first flushes messages already queued so ordering
+// is kept. It is off by default because logging calls then wait on the writers.
+func (l *Logger) SetSynchronous(on bool) {
+	if on {
+		l.Flush()
+	}
+	l.synchronous.Store(on)
+}
+
+// SetThreshold is SetMinLevel under the name used by other logging packages: it
+// sets the level below which messages of every group are dropped.
+// EnableTrace(true) is shorthand for SetThreshold(LevelTrace).
+func (l *Logger) SetThreshold(min Level) {
+	l.SetMinLevel(min)
+}
+
+// Trace logs a message to default group at trace level. Similar to fmt.Print(...)
+func (l *Logger) Trace(a ...interface{}) {
+	l.log(0, LevelTrace, "", a...)
+}
+
+// Trace logs a message to default group at trace level. Similar to fmt.Printf(...)
+func (l *Logger) Tracef(format string, a ...interface{}) {
+	l.log(0, LevelTrace, format, a...)
+}
+
+// Traceg logs a message to given group at trace level. Similar to fmt.Print(...)
+func (l *Logger) Traceg(group int, a ...interface{}) {
+	l.log(group, LevelTrace, "", a...)
+}
+
+// Tracegf logs a message to given group at trace level. Similar to fmt.Printf(...)
+func (l *Logger) Tracegf(group int, format string, a ...interface{}) {
+	l.log(group, LevelTrace, format, a...)
+}
+
+// TraceFunc logs the message returned by f to given group at trace level. f is
+// only called, on the calling goroutine, if trace and the group are enabled, so
+// expensive debug strings are not built while they would be dropped. Like
+// IsTraceEnabled it sees changes already processed by the log goroutine.
+func (l *Logger) TraceFunc(group int, f func() string) {
+	if l.enabled(group, LevelTrace) {
+		l.log(group, LevelTrace, "", f())
+	}
+}
+
+// UnregisterGroup removes a logging group. If the group's writers implement
+// io.Closer they are closed, except for os.Stdout and os.Stderr. Logs queued
+// before the call are still output; later logs to the group are dropped.
+// Group IDs are not reused and the default group cannot be removed.
+func (l *Logger) UnregisterGroup(group int) {
+	l.enqueue(&cmdUnregisterGroup{group})
+}
+
+// Warn logs a message to default group at warn level. Similar to fmt.Print(...)
+func (l *Logger) Warn(a ...interface{}) {
+	l.log(0, LevelWarn, "", a...)
+}
+
+// Warnf logs a message to default group at warn level. Similar to fmt.Printf(...)
+func (l *Logger) Warnf(format string, a ...interface{}) {
+	l.log(0, LevelWarn, format, a...)
+}
+
+// Warng logs a message to given group at warn level. Similar to fmt.Print(...)
+func (l *Logger) Warng(group int, a ...interface{}) {
+	l.log(group, LevelWarn, "", a...)
+}
+
+// Warngf logs a message to given group at warn level. Similar to fmt.Printf(...)
+func (l *Logger) Warngf(group int, format string, a ...interface{}) {
+	l.log(group, LevelWarn, format, a...)
+}
+
+// WithTrace turns trace level logging on, runs f, and then restores the
+// previous minimum level, even if f panics, so that trace is only output for
+// that code path. Logs queued before the call are output with the previous
+// level, and the change is visible to other goroutines logging while f runs.
+func (l *Logger) WithTrace(f func()) {
+	l.Flush()
+	prev := Level(l.minLevel.Load())
+	l.EnableTrace(true)
+	l.Flush()
+
+	defer func() {
+		l.SetMinLevel(prev)
+		l.Flush()
+	}()
+
+	f()
+}
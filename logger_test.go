@@ -0,0 +1,604 @@
+package trace
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Logger(t *testing.T) {
+	t.Parallel()
+
+	var first, second memoryLog
+	a := New(&first)
+	b := New(&second)
+
+	a.EnableTrace(true)
+	group := a.RegisterGroup("logger", &first, true)
+	if _, ok := b.GroupByName("logger"); ok {
+		t.Error("Logger failed: group registered on another Logger")
+	}
+
+	a.Trace("Test trace")
+	a.Infog(group, "Test group")
+	b.Trace("Test trace disabled")
+	b.WithFields(Fields{"key": "value"}).Info("Test fields")
+
+	a.Done()
+	b.Done()
+
+	goldFirst := []string{
+		timeFormat + ` TRACE Test trace`,
+		timeFormat + ` INFO \[logger\] Test group`,
+	}
+	goldSecond := []string{
+		timeFormat + ` INFO Test fields key=value`,
+	}
+
+	if len(first) != len(goldFirst) || len(second) != len(goldSecond) {
+		t.Fatal("Logger failed: recieved", first, "and", second)
+	}
+	for i, line := range first {
+		if match, err := regexp.MatchString(goldFirst[i], line); err != nil || !match {
+			t.Error("Logger failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+	for i, line := range second {
+		if match, err := regexp.MatchString(goldSecond[i], line); err != nil || !match {
+			t.Error("Logger failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_LoggerClose(t *testing.T) {
+	t.Parallel()
+
+	first := &closingLog{}
+	second := &closingLog{}
+	l := New(first)
+	l.AddGroupOutput(DefaultGroupId, first)
+	l.RegisterGroup("close", second, true)
+	l.SetErrorOutput(DefaultGroupId, second)
+
+	l.Close()
+
+	if !first.closed || !second.closed {
+		t.Error("Close failed: outputs were not closed")
+	}
+}
+
+// Registered once, as levels cannot be removed between runs of the tests
+var configAuditLevel = RegisterLevel("CONFIGAUDIT", int(LevelWarn))
+
+func Test_ConfigHandler(t *testing.T) {
+	t.Parallel()
+
+	var logMemFile memoryLog
+	l := New(&logMemFile)
+	group := l.RegisterGroup("config", &logMemFile, true)
+	handler := l.ConfigHandler()
+
+	request := func(method, body string) (int, string) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, "/", strings.NewReader(body)))
+		return w.Code, strings.TrimSpace(w.Body.String())
+	}
+
+	code, body := request(http.MethodGet, "")
+	gold := `{"trace":false,"minLevel":"INFO","groups":[{"id":0,"name":"","enabled":true},{"id":1,"name":"config","enabled":true}]}`
+	if code != http.StatusOK || body != gold {
+		t.Error("ConfigHandler failed: GET recieved", code, body)
+	}
+
+	code, body = request(http.MethodPost, `{"trace":true,"groups":{"config":false}}`)
+	gold = `{"trace":true,"minLevel":"TRACE","groups":[{"id":0,"name":"","enabled":true},{"id":1,"name":"config","enabled":false}]}`
+	if code != http.StatusOK || body != gold {
+		t.Error("ConfigHandler failed: POST recieved", code, body)
+	}
+
+	code, _ = request(http.MethodPost, `{"minLevel":"ERROR","groups":{"missing":true}}`)
+	if code != http.StatusBadRequest || !l.IsTraceEnabled() {
+		t.Error("ConfigHandler failed: expected unknown group to be rejected, recieved", code)
+	}
+
+	code, _ = request(http.MethodDelete, "")
+	if code != http.StatusMethodNotAllowed {
+		t.Error("ConfigHandler failed: expected DELETE to be rejected, recieved", code)
+	}
+
+	code, _ = request(http.MethodPost, `{"minLevel":""}`)
+	if code != http.StatusBadRequest || !l.IsTraceEnabled() {
+		t.Error("ConfigHandler failed: expected empty level to be rejected, recieved", code)
+	}
+
+	code, body = request(http.MethodPost, `{"minLevel":"`+configAuditLevel.String()+`"}`)
+	if code != http.StatusOK || !strings.Contains(body, `"minLevel":"WARN"`) {
+		t.Error("ConfigHandler failed: POST registered level recieved", code, body)
+	}
+	request(http.MethodPost, `{"minLevel":"TRACE"}`)
+
+	l.Tracef("Test trace")
+	l.Infog(group, "Test disabled")
+	l.Done()
+
+	if len(logMemFile) != 1 {
+		t.Error("ConfigHandler failed: expected 1 line, recieved", logMemFile)
+	}
+}
+
+func Test_SplitStdStreams(t *testing.T) {
+	stdout, stderr := os.Stdout, os.Stderr
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	var err error
+	dir := t.TempDir()
+	if os.Stdout, err = os.Create(dir + "/stdout"); err != nil {
+		t.Fatal(err)
+	}
+	if os.Stderr, err = os.Create(dir + "/stderr"); err != nil {
+		t.Fatal(err)
+	}
+
+	l := New(os.Stdout)
+	l.SetErrorOutput(DefaultGroupId, nil)
+	l.Warn("Test warn not split")
+	l.SplitStdStreams(true)
+	l.Info("Test info")
+	l.Warn("Test warn")
+	l.Error("Test error")
+	l.Done()
+
+	read := func(f *os.File) string {
+		f.Seek(0, io.SeekStart)
+		b, _ := io.ReadAll(f)
+		f.Close()
+		return string(b)
+	}
+	out, errOut := read(os.Stdout), read(os.Stderr)
+
+	goldOut := `^` + timeFormat + ` WARN Test warn not split\n` + timeFormat + ` INFO Test info\n$`
+	goldErr := `^` + timeFormat + ` WARN Test warn\n` + timeFormat + ` ERROR Test error\n$`
+	if match, err := regexp.MatchString(goldOut, out); err != nil || !match {
+		t.Error("SplitStdStreams failed: stdout Recieved:\n", out)
+	}
+	if match, err := regexp.MatchString(goldErr, errOut); err != nil || !match {
+		t.Error("SplitStdStreams failed: stderr Recieved:\n", errOut)
+	}
+}
+
+func Test_CaptureSplitStdStreams(t *testing.T) {
+	stdout, stderr := os.Stdout, os.Stderr
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	var err error
+	dir := t.TempDir()
+	if os.Stdout, err = os.Create(dir + "/stdout"); err != nil {
+		t.Fatal(err)
+	}
+	if os.Stderr, err = os.Create(dir + "/stderr"); err != nil {
+		t.Fatal(err)
+	}
+
+	var levelLog memoryLog
+	l := New(os.Stdout)
+	l.SetErrorOutput(DefaultGroupId, nil)
+	l.SplitStdStreams(true)
+	l.SetLevelOutput(DefaultGroupId, LevelInfo, &levelLog)
+
+	lines := l.Capture(func() {
+		l.Info("Test info")
+		l.Warn("Test warn")
+		l.Error("Test error")
+	})
+	l.Done()
+
+	gold := []string{
+		`^` + timeFormat + ` INFO Test info$`,
+		`^` + timeFormat + ` WARN Test warn$`,
+		`^` + timeFormat + ` ERROR Test error$`,
+	}
+
+	if len(lines) != len(gold) {
+		t.Fatal("CaptureSplitStdStreams failed: expected", len(gold), "lines, recieved", lines)
+	}
+	for i, line := range lines {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("CaptureSplitStdStreams failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		if info, err := f.Stat(); err != nil || info.Size() != 0 {
+			t.Error("CaptureSplitStdStreams failed: captured lines written to", f.Name())
+		}
+		f.Close()
+	}
+	if len(levelLog) != 0 {
+		t.Error("CaptureSplitStdStreams failed: captured lines written to level output", levelLog)
+	}
+}
+
+func Test_SetSyncWritesPipe(t *testing.T) {
+	t.Parallel()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	go io.Copy(io.Discard, r)
+
+	var errs []error
+	l := New(w)
+	l.SetErrorHandler(func(group int, err error) {
+		errs = append(errs, err)
+	})
+	l.SetSyncWrites(DefaultGroupId, true)
+	l.Info("Test synced pipe")
+	l.Done()
+	w.Close()
+
+	if len(errs) != 0 {
+		t.Error("SetSyncWrites failed: expected no errors syncing a pipe, recieved", errs)
+	}
+}
+
+// newBenchLogger is a helper function for a Logger whose default group
+// formats every line and throws it away
+func newBenchLogger() *Logger {
+	return New(WriterFunc(func(p []byte) (int, error) { return len(p), nil }))
+}
+
+func BenchmarkInfo(b *testing.B) {
+	l := newBenchLogger()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Infof("Benchmark %d", i)
+	}
+	l.Done()
+}
+
+func BenchmarkInfoDisabled(b *testing.B) {
+	l := newBenchLogger()
+	l.EnableDefault(false)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Infof("Benchmark %d", i)
+	}
+	l.Done()
+}
+
+func BenchmarkTraceDisabled(b *testing.B) {
+	l := newBenchLogger()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Tracef("Benchmark %d", i)
+	}
+	l.Done()
+}
+
+func Test_LogAfterDone(t *testing.T) {
+	t.Parallel()
+
+	var logMemFile memoryLog
+	l := New(&logMemFile)
+	l.Info("Test before")
+	l.Done()
+
+	l.Info("Test after")
+	l.Errorf("Test %s", "after")
+	l.WithFields(Fields{"key": "value"}).Warn("Test after")
+
+	// Commands run on the calling goroutine instead of panicking
+	if _, err := l.RegisterGroupE("after", &logMemFile, true); err != nil {
+		t.Error("LogAfterDone failed: RegisterGroupE returned", err)
+	}
+	l.SetDefaultGroup(&logMemFile)
+	l.Flush()
+
+	if len(logMemFile) != 1 {
+		t.Error("LogAfterDone failed: expected 1 line, recieved", logMemFile)
+	}
+}
+
+func Test_PauseResume(t *testing.T) {
+	t.Parallel()
+
+	var logMemFile memoryLog
+	l := New(&logMemFile)
+	l.Info("Test before")
+	l.Flush()
+
+	l.Pause()
+	l.Info("Test first paused")
+	l.Info("Test second paused")
+	time.Sleep(5 * time.Millisecond)
+
+	l.mu.Lock()
+	paused := len(logMemFile)
+	l.mu.Unlock()
+	if paused != 1 {
+		t.Error("PauseResume failed: expected 1 line while paused, recieved", paused)
+	}
+
+	l.Resume()
+	l.Info("Test after")
+	l.Pause()
+	l.Info("Test done while paused")
+	l.Done()
+
+	gold := []string{
+		`INFO Test before\n$`,
+		`INFO Test first paused\n$`,
+		`INFO Test second paused\n$`,
+		`INFO Test after\n$`,
+		`INFO Test done while paused\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("PauseResume failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("PauseResume failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_TickSummaries(t *testing.T) {
+	t.Parallel()
+
+	var limitedLog, dedupLog memoryLog
+	l := New(&limitedLog)
+	group := l.RegisterGroup("ticksummary", &dedupLog, true)
+
+	l.SetClock(func() time.Time {
+		return time.Date(2024, 3, 5, 10, 20, 30, 0, time.UTC)
+	})
+	l.SetRateLimit(DefaultGroupId, 1)
+	l.SetDedup(group, true)
+	for i := 0; i < 3; i++ {
+		l.Info("Test burst")
+		l.Infog(group, "Test repeat")
+	}
+	l.SetFlushInterval(time.Millisecond)
+
+	// No further messages are logged, so only the tick writes the summaries
+	var limited, deduped []string
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		l.mu.Lock()
+		limited = append(limited[:0], limitedLog...)
+		deduped = append(deduped[:0], dedupLog...)
+		l.mu.Unlock()
+		if len(limited) == 2 && len(deduped) == 2 {
+			break
+		}
+	}
+	l.Done()
+
+	if len(limited) != 2 || !strings.HasSuffix(limited[1], "WARN ... 2 messages suppressed\n") {
+		t.Error("TickSummaries failed: rate limit recieved", limited)
+	}
+	if len(deduped) != 2 || !strings.HasSuffix(deduped[1], "last message repeated 2 times\n") {
+		t.Error("TickSummaries failed: dedup recieved", deduped)
+	}
+}
+
+func Test_SetMinLevelThresholds(t *testing.T) {
+	t.Parallel()
+
+	setters := map[string]func(l *Logger, min Level){
+		"SetMinLevel":  (*Logger).SetMinLevel,
+		"SetThreshold": (*Logger).SetThreshold,
+	}
+
+	for name, set := range setters {
+		for threshold := LevelTrace; threshold <= LevelError; threshold++ {
+			var logMemFile memoryLog
+			l := New(&logMemFile)
+			l.SetErrorOutput(DefaultGroupId, nil)
+
+			set(l, threshold)
+			l.Trace("Test")
+			l.Info("Test")
+			l.Warn("Test")
+			l.Error("Test")
+			l.Done()
+
+			var gold []string
+			for lvl := threshold; lvl <= LevelError; lvl++ {
+				gold = append(gold, timeFormat+` `+lvl.String()+` Test\n$`)
+			}
+
+			if len(logMemFile) != len(gold) {
+				t.Error(name, "failed: threshold", threshold, "expected", len(gold), "lines, recieved", logMemFile)
+				continue
+			}
+			for i, line := range logMemFile {
+				if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+					t.Error(name, "failed: threshold", threshold, "Line mismatch on line", i+1, "Recieved:\n", line)
+				}
+			}
+		}
+	}
+}
+
+func Test_EnabledWithoutLock(t *testing.T) {
+	t.Parallel()
+
+	var logMemFile memoryLog
+	l := New(&logMemFile)
+	group := l.RegisterGroup("enabledlock", &logMemFile, true)
+	l.SetGroupLevel(group, LevelWarn)
+	l.Flush()
+
+	// mu is held while lines are written, which must not block the check
+	l.mu.Lock()
+	result := make(chan [3]bool, 1)
+	go func() {
+		result <- [3]bool{l.enabled(group, LevelInfo), l.enabled(group, LevelWarn), l.enabled(group+1, LevelWarn)}
+	}()
+	select {
+	case got := <-result:
+		if got != [3]bool{false, true, false} {
+			t.Error("enabled failed: recieved", got)
+		}
+	case <-time.After(time.Second):
+		t.Error("enabled failed: blocked while mu was held")
+	}
+	l.mu.Unlock()
+
+	l.EnableGroup(group, false)
+	l.Flush()
+	if l.enabled(group, LevelError) {
+		t.Error("enabled failed: disabled group reported enabled")
+	}
+	l.Done()
+}
+
+func Test_ResetWhileRunning(t *testing.T) {
+	t.Parallel()
+
+	var logMemFile memoryLog
+	l := New(&logMemFile)
+
+	// A second log goroutine would keep reading the old stream, so Done would never return
+	l.reset()
+	l.Info("Test once")
+
+	done := make(chan struct{})
+	go func() {
+		l.Done()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reset failed: started a second log goroutine")
+	}
+
+	if len(logMemFile) != 1 {
+		t.Error("reset failed: expected 1 line, recieved", logMemFile)
+	}
+}
+
+func Test_ColorAutoBatched(t *testing.T) {
+	t.Parallel()
+
+	// /dev/null is a character device, so ColorAuto treats it like a terminal
+	device, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip("ColorAutoBatched skipped:", err)
+	}
+	defer device.Close()
+
+	l := New(device)
+	l.SetColor(ColorAuto)
+	l.Flush()
+
+	l.mu.Lock()
+	direct, batch := l.useColor(device), l.useColor(batched(device))
+	l.mu.Unlock()
+	l.Done()
+
+	if !direct || !batch {
+		t.Error("ColorAutoBatched failed: recieved", direct, "unbatched and", batch, "batched")
+	}
+}
+
+func Test_RingBufferLengthPrefixed(t *testing.T) {
+	t.Parallel()
+
+	var logMemFile memoryLog
+	l := New(&logMemFile)
+	l.SetFormat(FormatLengthPrefixed)
+	l.EnableRingBuffer(DefaultGroupId, 2)
+	l.Info("Test framed")
+	l.Flush()
+
+	lines := l.RingBuffer(DefaultGroupId)
+	l.Done()
+
+	if len(logMemFile) != 1 || !strings.HasSuffix(logMemFile[0], "INFO Test framed") {
+		t.Error("RingBufferLengthPrefixed failed: output recieved", logMemFile)
+	}
+	if len(lines) != 1 {
+		t.Fatal("RingBufferLengthPrefixed failed: expected 1 line, recieved", lines)
+	}
+	if match, err := regexp.MatchString(`^`+timeFormat+` INFO Test framed$`, lines[0]); err != nil || !match {
+		t.Errorf("RingBufferLengthPrefixed failed: Line mismatch Recieved:\n %q", lines[0])
+	}
+}
+
+func Test_GroupStatsDroppedGroups(t *testing.T) {
+	t.Parallel()
+
+	blocker := &blockingLog{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	l := New(blocker)
+	l.SetBufferSize(1)
+
+	var logMemFile memoryLog
+	first := l.RegisterGroup("droppedfirst", &logMemFile, true)
+	second := l.RegisterGroup("droppedsecond", &logMemFile, true)
+	l.SetOverflowPolicy(PolicyDrop)
+
+	l.Info("Test blocks the log goroutine")
+	<-blocker.entered
+	l.InfoGroups([]int{first, second}, "Test queued")
+	l.InfoGroups([]int{first, second}, "Test dropped")
+
+	close(blocker.release)
+	stats := [3]uint64{l.GroupStats(DefaultGroupId).Dropped, l.GroupStats(first).Dropped, l.GroupStats(second).Dropped}
+	l.Done()
+
+	if stats != [3]uint64{0, 1, 1} || l.DroppedCount() != 1 {
+		t.Error("GroupStats failed: expected a drop for each group, recieved", stats, l.DroppedCount())
+	}
+}
+
+func Test_LogLevels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		lvl     Level
+		helper  func(l *Logger, group int, a ...interface{})
+		helperf func(l *Logger, group int, format string, a ...interface{})
+	}{
+		{LevelTrace, (*Logger).Traceg, (*Logger).Tracegf},
+		{LevelInfo, (*Logger).Infog, (*Logger).Infogf},
+		{LevelWarn, (*Logger).Warng, (*Logger).Warngf},
+		{LevelError, (*Logger).Errorg, (*Logger).Errorgf},
+	}
+
+	for _, test := range tests {
+		var logMemFile memoryLog
+		l := New(&logMemFile)
+		l.SetErrorOutput(DefaultGroupId, nil)
+		l.EnableTrace(true)
+		l.EnableCaller(true)
+
+		l.Log(DefaultGroupId, test.lvl, "Test ", "log")
+		l.Logf(DefaultGroupId, test.lvl, "Test %s", "log")
+		test.helper(l, DefaultGroupId, "Test ", "log")
+		test.helperf(l, DefaultGroupId, "Test %s", "log")
+		l.Done()
+
+		// The helpers and Log are called from the same depth, so the caller is this file for all of them
+		gold := timeFormat + ` ` + test.lvl.String() + ` logger_test.go:\d+ Test log\n$`
+		if len(logMemFile) != 4 {
+			t.Error("Log failed: level", test.lvl, "expected 4 lines, recieved", logMemFile)
+			continue
+		}
+		for i, line := range logMemFile {
+			if match, err := regexp.MatchString(gold, line); err != nil || !match {
+				t.Error("Log failed: level", test.lvl, "Line mismatch on line", i+1, "Recieved:\n", line)
+			}
+		}
+	}
+}
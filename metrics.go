@@ -0,0 +1,29 @@
+package trace
+
+type cmdSetMetricsHook struct {
+	hook func(group int, level Level)
+}
+
+func (c *cmdSetMetricsHook) do(l *Logger) {
+	l.metricsHook = c.hook
+}
+
+// SetMetricsHook sets a function called for every message that is output,
+// after the group, level, rate limit, and other checks, so that counters such
+// as Prometheus metrics can be kept per group and level. Summary lines such as
+// "... 3 messages suppressed" are not counted. The hook runs serially on the
+// log goroutine, so it needs no locking but must be fast and must not block or
+// log. A nil hook removes it.
+func (l *Logger) SetMetricsHook(hook func(group int, level Level)) {
+	l.enqueue(&cmdSetMetricsHook{hook})
+}
+
+// SetMetricsHook sets a function called for every message that is output,
+// after the group, level, rate limit, and other checks, so that counters such
+// as Prometheus metrics can be kept per group and level. Summary lines such as
+// "... 3 messages suppressed" are not counted. The hook runs serially on the
+// log goroutine, so it needs no locking but must be fast and must not block or
+// log. A nil hook removes it.
+func SetMetricsHook(hook func(group int, level Level)) {
+	std.SetMetricsHook(hook)
+}
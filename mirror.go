@@ -0,0 +1,37 @@
+package trace
+
+import "io"
+
+type cmdSetMirror struct {
+	w io.Writer
+}
+
+func (c *cmdSetMirror) do(l *Logger) {
+	l.mirror = c.w
+}
+
+// SetMirror also writes every line output by any group, at any level, to w,
+// whatever the outputs of the groups, for example to tail all logs live while
+// investigating an incident. Lines dropped by a group's settings are not
+// mirrored. The mirror is never closed by the package. See ClearMirror.
+func (l *Logger) SetMirror(w io.Writer) {
+	l.enqueue(&cmdSetMirror{w})
+}
+
+// ClearMirror stops writing lines to the writer set with SetMirror
+func (l *Logger) ClearMirror() {
+	l.enqueue(&cmdSetMirror{nil})
+}
+
+// SetMirror also writes every line output by any group, at any level, to w,
+// whatever the outputs of the groups, for example to tail all logs live while
+// investigating an incident. Lines dropped by a group's settings are not
+// mirrored. The mirror is never closed by the package. See ClearMirror.
+func SetMirror(w io.Writer) {
+	std.SetMirror(w)
+}
+
+// ClearMirror stops writing lines to the writer set with SetMirror
+func ClearMirror() {
+	std.ClearMirror()
+}
@@ -0,0 +1,44 @@
+package trace
+
+import "sync"
+
+// Log messages are reused once the log goroutine is done with them, so that
+// logging calls do not allocate one each. Indexed by Level
+var msgPools = [...]sync.Pool{
+	LevelTrace: {New: func() interface{} { return new(traceMsg) }},
+	LevelInfo:  {New: func() interface{} { return new(infoMsg) }},
+	LevelWarn:  {New: func() interface{} { return new(warnMsg) }},
+	LevelError: {New: func() interface{} { return new(errorMsg) }},
+}
+
+// newMsg is a helper function for taking a log message of the given level from
+// its pool. Levels added with RegisterLevel are not pooled.
+func newMsg(lvl Level, data msgData) logApi {
+	if lvl < LevelTrace || lvl > LevelError {
+		return &customMsg{data, lvl}
+	}
+
+	switch m := msgPools[lvl].Get().(type) {
+	case *traceMsg:
+		m.msgData = data
+		return m
+	case *infoMsg:
+		m.msgData = data
+		return m
+	case *warnMsg:
+		m.msgData = data
+		return m
+	case *errorMsg:
+		m.msgData = data
+		return m
+	}
+	return nil
+}
+
+// releaseMsg is a helper function for returning a log message to its pool. The
+// message is cleared first so that the pool does not keep its arguments alive.
+// It must not be used afterwards.
+func releaseMsg(lvl Level, cmd logApi, m *msgData) {
+	*m = msgData{}
+	msgPools[lvl].Put(cmd)
+}
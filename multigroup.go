@@ -0,0 +1,36 @@
+package trace
+
+// printGroups is a helper function for outputting a message to each of its
+// groups that allows the level. The message is formatted only once.
+func (l *Logger) printGroups(lvl Level, m *msgData) {
+	if !l.allows(lvl) {
+		return
+	}
+	for _, group := range m.groups {
+		if g := l.getGroup(group); g != nil && g.allows(lvl) {
+			m.group = group
+			l.printLog(lvl, m)
+		}
+	}
+}
+
+// logGroups is a helper function for processing log requests to several groups
+func (l *Logger) logGroups(groups []int, lvl Level, format string, a ...interface{}) {
+	l.send(lvl, msgData{groups: append([]int{}, groups...)}, format, a...)
+}
+
+// InfoGroups logs a message at info level to each of the given groups, for
+// events that belong to several, such as both an audit and a security group.
+// The message is queued and formatted once, and every copy has the same
+// timestamp. Disabled groups are skipped. Similar to fmt.Printf(...)
+func (l *Logger) InfoGroups(groups []int, format string, a ...interface{}) {
+	l.logGroups(groups, LevelInfo, format, a...)
+}
+
+// InfoGroups logs a message at info level to each of the given groups, for
+// events that belong to several, such as both an audit and a security group.
+// The message is queued and formatted once, and every copy has the same
+// timestamp. Disabled groups are skipped. Similar to fmt.Printf(...)
+func InfoGroups(groups []int, format string, a ...interface{}) {
+	std.logGroups(groups, LevelInfo, format, a...)
+}
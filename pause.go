@@ -0,0 +1,53 @@
+package trace
+
+// Pause halts all output until Resume. Log messages and other requests are
+// still accepted and queued, up to the buffer size set with SetBufferSize,
+// after which they block or are dropped as set with SetOverflowPolicy. Nothing
+// is written while paused, including lines held back by SetBatch, and calls
+// that wait for the log goroutine, such as Flush, RegisterGroup, or
+// GroupStats, block until Resume. Done and DoneTimeout resume output. Pause has
+// no effect in synchronous mode.
+func (l *Logger) Pause() {
+	l.mu.Lock()
+	l.paused.Store(true)
+	l.mu.Unlock()
+}
+
+// Resume restarts output halted by Pause. The queued messages are written in
+// the order they were logged, before any logged afterwards.
+func (l *Logger) Resume() {
+	if !l.paused.Load() {
+		return
+	}
+
+	l.mu.Lock()
+	l.paused.Store(false)
+	held := l.held
+	l.held = nil
+	for _, i := range held {
+		i.do(l)
+	}
+	l.mu.Unlock()
+
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Pause halts all output until Resume. Log messages and other requests are
+// still accepted and queued, up to the buffer size set with SetBufferSize,
+// after which they block or are dropped as set with SetOverflowPolicy. Nothing
+// is written while paused, including lines held back by SetBatch, and calls
+// that wait for the log goroutine, such as Flush, RegisterGroup, or
+// GroupStats, block until Resume. Done and DoneTimeout resume output. Pause has
+// no effect in synchronous mode.
+func Pause() {
+	std.Pause()
+}
+
+// Resume restarts output halted by Pause. The queued messages are written in
+// the order they were logged, before any logged afterwards.
+func Resume() {
+	std.Resume()
+}
@@ -0,0 +1,87 @@
+package trace
+
+import (
+	"os"
+	"sync"
+)
+
+// ID of this process, written by EnablePID
+var pid = os.Getpid()
+
+// Name of this host, written by EnableHostname. It is looked up once, on first
+// use, since the lookup can be slow. Empty if the lookup failed
+var (
+	hostname     string
+	hostnameOnce sync.Once
+)
+
+type cmdEnablePID struct {
+	on bool
+}
+
+func (c *cmdEnablePID) do(l *Logger) {
+	l.pidEnabled = c.on
+}
+
+// pid is a helper function for the process ID to write, or zero when EnablePID is off
+func (l *Logger) pid() int {
+	if !l.pidEnabled {
+		return 0
+	}
+	return pid
+}
+
+// EnablePID turns on or off writing the process ID in every line, like
+// [pid=1234] after the group, so that the lines of processes sharing a log file
+// can be told apart. FormatJSON and FormatLogfmt write it as the pid key. It is
+// off by default.
+func (l *Logger) EnablePID(on bool) {
+	l.enqueue(&cmdEnablePID{on})
+}
+
+// EnablePID turns on or off writing the process ID in every line, like
+// [pid=1234] after the group, so that the lines of processes sharing a log file
+// can be told apart. FormatJSON and FormatLogfmt write it as the pid key. It is
+// off by default.
+func EnablePID(on bool) {
+	std.EnablePID(on)
+}
+
+type cmdEnableHostname struct {
+	on bool
+}
+
+func (c *cmdEnableHostname) do(l *Logger) {
+	if c.on {
+		hostnameOnce.Do(func() {
+			hostname, _ = os.Hostname()
+		})
+	}
+	l.hostnameEnabled = c.on
+}
+
+// host is a helper function for the hostname to write, or "" when EnableHostname is off
+func (l *Logger) host() string {
+	if !l.hostnameEnabled {
+		return ""
+	}
+	return hostname
+}
+
+// EnableHostname turns on or off writing the hostname in every line, like
+// [host=web1] after the group, for collectors that gather the logs of many
+// machines without adding the source host. FormatJSON and FormatLogfmt write
+// it as the host key. The hostname is looked up once, and left out if the
+// lookup fails. It is off by default.
+func (l *Logger) EnableHostname(on bool) {
+	l.enqueue(&cmdEnableHostname{on})
+}
+
+// EnableHostname turns on or off writing the hostname in every line, like
+// [host=web1] after the group, for collectors that gather the logs of many
+// machines without adding the source host. FormatJSON and FormatLogfmt write
+// it as the host key. The hostname is looked up once, and left out if the
+// lookup fails. It is off by default.
+func EnableHostname(on bool) {
+	std.EnableHostname(on)
+}
@@ -0,0 +1,111 @@
+package trace
+
+import (
+	"fmt"
+	"time"
+)
+
+// rateLimit is a token bucket limiting the messages a group outputs per second.
+// It is only used on the log goroutine so it needs no locking.
+type rateLimit struct {
+	perSecond int
+	tokens    float64
+	last      time.Time
+
+	// Messages dropped since the last summary line
+	pending uint64
+
+	// Messages dropped since the limit was set
+	total uint64
+}
+
+// allow is a helper function for taking a token for a message logged at t
+func (r *rateLimit) allow(t time.Time) bool {
+	if elapsed := t.Sub(r.last); elapsed > 0 {
+		r.tokens += elapsed.Seconds() * float64(r.perSecond)
+		if r.tokens > float64(r.perSecond) {
+			r.tokens = float64(r.perSecond)
+		}
+		r.last = t
+	}
+
+	if r.tokens < 1 {
+		r.pending++
+		r.total++
+		return false
+	}
+	r.tokens--
+	return true
+}
+
+type cmdSetRateLimit struct {
+	group     int
+	perSecond int
+}
+
+func (c *cmdSetRateLimit) do(l *Logger) {
+	g := l.getGroup(c.group)
+	if g == nil {
+		return
+	}
+
+	l.writeSuppressed(c.group, l.now())
+	if c.perSecond <= 0 {
+		g.limit = nil
+		return
+	}
+	g.limit = &rateLimit{perSecond: c.perSecond, tokens: float64(c.perSecond), last: l.now()}
+}
+
+// writeSuppressed is a helper function for writing the summary line of messages
+// the group's rate limit dropped since the last summary.
+func (l *Logger) writeSuppressed(group int, t time.Time) {
+	g := l.groups[group]
+	if g.limit == nil || g.limit.pending == 0 {
+		return
+	}
+
+	summary := msgData{group: group, t: t, msg: fmt.Sprintf("... %d messages suppressed", g.limit.pending)}
+	g.limit.pending = 0
+	l.writeMsg(LevelWarn, &summary)
+}
+
+// SetRateLimit limits the group to perSecond messages per second so that a
+// misbehaving loop cannot flood the output. Messages above the limit are
+// dropped and a warn level line such as "... 42 messages suppressed" is written
+// before the next message that is output, at least once a second, or as set
+// with SetFlushInterval, on Flush, and on Done. A perSecond of zero removes the
+// limit. Groups are not limited by default.
+func (l *Logger) SetRateLimit(group int, perSecond int) {
+	l.enqueue(&cmdSetRateLimit{group, perSecond})
+}
+
+// SetRateLimit limits the group to perSecond messages per second so that a
+// misbehaving loop cannot flood the output. Messages above the limit are
+// dropped and a warn level line such as "... 42 messages suppressed" is written
+// before the next message that is output, at least once a second, or as set
+// with SetFlushInterval, on Flush, and on Done. A perSecond of zero removes the
+// limit. Groups are not limited by default.
+func SetRateLimit(group int, perSecond int) {
+	std.SetRateLimit(group, perSecond)
+}
+
+// SuppressedCount returns the number of messages of the group dropped by its
+// rate limit since SetRateLimit was called. Like IsGroupEnabled it reflects
+// messages already processed by the log goroutine.
+func (l *Logger) SuppressedCount(group int) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if g := l.getGroup(group); g != nil && g.limit != nil {
+		return g.limit.total
+	}
+	return 0
+}
+
+// SuppressedCount returns the number of messages of the group dropped by its
+// rate limit since SetRateLimit was called. Like IsGroupEnabled it reflects
+// messages already processed by the log goroutine.
+func SuppressedCount(group int) uint64 {
+	return std.SuppressedCount(group)
+}
@@ -0,0 +1,27 @@
+package trace
+
+type cmdSetRedactor struct {
+	redactor func(msg string) string
+}
+
+func (c *cmdSetRedactor) do(l *Logger) {
+	l.redactor = c.redactor
+}
+
+// SetRedactor sets a function that receives every formatted message before it
+// is output and returns the message to output instead, for example to remove
+// email addresses or tokens. It applies to all groups and levels, but not to
+// fields. The redactor runs serially on the log goroutine, so it needs no
+// locking but must be fast and must not log. A nil redactor removes it.
+func (l *Logger) SetRedactor(redactor func(msg string) string) {
+	l.enqueue(&cmdSetRedactor{redactor})
+}
+
+// SetRedactor sets a function that receives every formatted message before it
+// is output and returns the message to output instead, for example to remove
+// email addresses or tokens. It applies to all groups and levels, but not to
+// fields. The redactor runs serially on the log goroutine, so it needs no
+// locking but must be fast and must not log. A nil redactor removes it.
+func SetRedactor(redactor func(msg string) string) {
+	std.SetRedactor(redactor)
+}
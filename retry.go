@@ -0,0 +1,81 @@
+package trace
+
+import (
+	"io"
+	"time"
+)
+
+// Longest time the retries of one failed write may block the log goroutine
+const maxRetryTime = 5 * time.Second
+
+type cmdSetWriteRetry struct {
+	group    int
+	attempts int
+	backoff  time.Duration
+}
+
+func (c *cmdSetWriteRetry) do(l *Logger) {
+	if g := l.getGroup(c.group); g != nil {
+		g.retryAttempts = c.attempts
+		g.retryBackoff = c.backoff
+	}
+}
+
+// writeRetried is a helper function for writing a line, retrying as set with
+// SetWriteRetry while the output fails. A partly written line is retried
+// from where the write stopped.
+func writeRetried(g *groupData, lvl Level, output io.Writer, line []byte) error {
+	err := writeOnce(lvl, output, &line)
+	if err == nil || g == nil {
+		return err
+	}
+
+	backoff := g.retryBackoff
+	deadline := time.Now().Add(maxRetryTime)
+	for attempt := 0; attempt < g.retryAttempts && err != nil; attempt++ {
+		if time.Now().Add(backoff).After(deadline) {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		err = writeOnce(lvl, output, &line)
+	}
+	return err
+}
+
+// writeOnce is a helper function for writing a line to an output, leaving in
+// line what was not written
+func writeOnce(lvl Level, output io.Writer, line *[]byte) error {
+	if w, ok := output.(levelWriter); ok {
+		_, err := w.writeLevel(lvl.base(), *line)
+		return err
+	}
+
+	n, err := output.Write(*line)
+	if err != nil && n > 0 && n < len(*line) {
+		*line = (*line)[n:]
+	}
+	return err
+}
+
+// SetWriteRetry makes the group retry a failed write up to attempts times
+// before reporting the error to the SetErrorHandler function, for outputs with
+// transient failures such as network writers. It waits backoff before the
+// first retry and twice as long before each next one. Retries block the log
+// goroutine, so while they last logging calls queue up and then block or drop
+// messages per SetOverflowPolicy; retries of one write stop after 5 seconds in
+// total. Zero attempts, the default, turns retrying off.
+func (l *Logger) SetWriteRetry(group int, attempts int, backoff time.Duration) {
+	l.enqueue(&cmdSetWriteRetry{group, attempts, backoff})
+}
+
+// SetWriteRetry makes the group retry a failed write up to attempts times
+// before reporting the error to the SetErrorHandler function, for outputs with
+// transient failures such as network writers. It waits backoff before the
+// first retry and twice as long before each next one. Retries block the log
+// goroutine, so while they last logging calls queue up and then block or drop
+// messages per SetOverflowPolicy; retries of one write stop after 5 seconds in
+// total. Zero attempts, the default, turns retrying off.
+func SetWriteRetry(group int, attempts int, backoff time.Duration) {
+	std.SetWriteRetry(group, attempts, backoff)
+}
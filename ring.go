@@ -0,0 +1,95 @@
+package trace
+
+import "strings"
+
+// ringBuffer keeps the last lines written to a group, overwriting the oldest
+type ringBuffer struct {
+	lines []string
+
+	// Index the next line is written at, and whether lines has wrapped around
+	next int
+	full bool
+}
+
+func (r *ringBuffer) add(line string) {
+	r.lines[r.next] = line
+	r.next++
+	if r.next == len(r.lines) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// snapshot returns a copy of the lines, oldest first
+func (r *ringBuffer) snapshot() []string {
+	if !r.full {
+		return append([]string(nil), r.lines[:r.next]...)
+	}
+	return append(append([]string(nil), r.lines[r.next:]...), r.lines[:r.next]...)
+}
+
+type cmdEnableRingBuffer struct {
+	group int
+	size  int
+}
+
+func (c *cmdEnableRingBuffer) do(l *Logger) {
+	g := l.getGroup(c.group)
+	if g == nil {
+		return
+	}
+
+	if c.size <= 0 {
+		g.ring = nil
+		return
+	}
+	g.ring = &ringBuffer{lines: make([]string, c.size)}
+}
+
+// writeRing is a helper function for keeping a formatted line in the group's ring buffer, if any
+func (g *groupData) writeRing(line []byte) {
+	if g.ring != nil {
+		g.ring.add(strings.TrimSuffix(string(line), "\n"))
+	}
+}
+
+// EnableRingBuffer makes the group keep its last size lines in memory, as
+// formatted for its outputs but without color or the trailing newline, so that
+// they can be read with RingBuffer, for example by a debug endpoint. Under
+// FormatLengthPrefixed the lines are kept as FormatText, without the frame.
+// Lines are kept even if the group writes to io.Discard. Enabling it again
+// empties the buffer, and a size of 0 turns it off, which is the default.
+func (l *Logger) EnableRingBuffer(group int, size int) {
+	l.enqueue(&cmdEnableRingBuffer{group, size})
+}
+
+// RingBuffer returns a copy of the lines kept by the group's ring buffer,
+// oldest first. It returns nil if the group has no ring buffer. It is safe to
+// call concurrently with logging.
+func (l *Logger) RingBuffer(group int) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	g := l.getGroup(group)
+	if g == nil || g.ring == nil {
+		return nil
+	}
+	return g.ring.snapshot()
+}
+
+// EnableRingBuffer makes the group keep its last size lines in memory, as
+// formatted for its outputs but without color or the trailing newline, so that
+// they can be read with RingBuffer, for example by a debug endpoint. Under
+// FormatLengthPrefixed the lines are kept as FormatText, without the frame.
+// Lines are kept even if the group writes to io.Discard. Enabling it again
+// empties the buffer, and a size of 0 turns it off, which is the default.
+func EnableRingBuffer(group int, size int) {
+	std.EnableRingBuffer(group, size)
+}
+
+// RingBuffer returns a copy of the lines kept by the group's ring buffer,
+// oldest first. It returns nil if the group has no ring buffer. It is safe to
+// call concurrently with logging.
+func RingBuffer(group int) []string {
+	return std.RingBuffer(group)
+}
@@ -0,0 +1,202 @@
+package trace
+
+import (
+	"compress/gzip"
+	"os"
+)
+
+// DefaultRotationSize is the size in bytes at which a file group is rotated
+// unless changed with SetRotationSize
+const DefaultRotationSize = 100 << 20
+
+// rotatingFile is an io.Writer to a file that is renamed to path.1 and
+// reopened once it grows past maxSize. It is only used on the log goroutine,
+// so it needs no locking.
+type rotatingFile struct {
+	path    string
+	file    *os.File
+	size    int64
+	maxSize int64
+
+	// Whether lines are gzip compressed. Each flush(true) ends a gzip member and
+	// the next write starts a new one, which gzip readers read as one stream
+	compress bool
+	zw       *gzip.Writer
+}
+
+// openRotatingFile is a helper function for opening or appending to a rotating file
+func openRotatingFile(path string, compress bool) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxSize: DefaultRotationSize, compress: compress}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.flush(true); err != nil {
+		return err
+	}
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Write(p []byte) (n int, err error) {
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	if !r.compress {
+		return r.writeFile(p)
+	}
+	if r.zw == nil {
+		r.zw = gzip.NewWriter(WriterFunc(r.writeFile))
+	}
+	return r.zw.Write(p)
+}
+
+// writeFile is a helper function for writing to the file and counting its size
+func (r *rotatingFile) writeFile(p []byte) (n int, err error) {
+	n, err = r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// flush writes the compressed lines still held by the gzip writer. If final is
+// set the gzip member is ended so that the file is a complete archive.
+func (r *rotatingFile) flush(final bool) error {
+	if r.zw == nil {
+		return nil
+	}
+	if !final {
+		return r.zw.Flush()
+	}
+
+	err := r.zw.Close()
+	r.zw = nil
+	return err
+}
+
+// Sync writes the compressed lines still held by the gzip writer and commits
+// the file to stable storage
+func (r *rotatingFile) Sync() error {
+	if err := r.flush(false); err != nil {
+		return err
+	}
+	return r.file.Sync()
+}
+
+func (r *rotatingFile) Close() error {
+	err := r.flush(true)
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+type cmdSetRotationSize struct {
+	group int
+	bytes int64
+}
+
+func (c *cmdSetRotationSize) do(l *Logger) {
+	if g := l.getGroup(c.group); g != nil {
+		for _, output := range g.outputs {
+			if r, ok := unbatched(output).(*rotatingFile); ok {
+				r.maxSize = c.bytes
+			}
+		}
+	}
+}
+
+// RegisterFileGroup registers a new logging group that appends to the file at path,
+// creating it if needed. When the file grows past DefaultRotationSize it is renamed
+// to path.1, replacing any previous path.1, and a fresh file is opened. It returns
+// ErrGroupExists, and the existing group's ID, if the group name already exists.
+func (l *Logger) RegisterFileGroup(name, path string, on bool) (int, error) {
+	return l.registerFileGroup(name, path, on, false)
+}
+
+// RegisterGzipFileGroup registers a new logging group like RegisterFileGroup,
+// but gzip compresses the lines written to the file, so path should end in
+// ".gz". Compressed lines are written at least once a second, or as set with
+// SetFlushInterval, and on Flush, and Done completes the archive; logging after
+// Reset appends a new gzip member, which gzip readers read as part of the same
+// file. The file is rotated like RegisterFileGroup, by its compressed size.
+func (l *Logger) RegisterGzipFileGroup(name, path string, on bool) (int, error) {
+	return l.registerFileGroup(name, path, on, true)
+}
+
+// registerFileGroup is a helper function for registering a group writing to a rotating file
+func (l *Logger) registerFileGroup(name, path string, on bool, compress bool) (int, error) {
+	if id, ok := l.GroupByName(name); ok {
+		return id, ErrGroupExists
+	}
+
+	r, err := openRotatingFile(path, compress)
+	if err != nil {
+		return 0, err
+	}
+
+	group, err := l.RegisterGroupE(name, r, on)
+	if err != nil {
+		r.Close()
+	}
+	return group, err
+}
+
+// SetRotationSize sets the size in bytes at which a group registered with
+// RegisterFileGroup is rotated. Zero disables rotation. It has no effect on
+// other groups.
+func (l *Logger) SetRotationSize(group int, bytes int64) {
+	l.enqueue(&cmdSetRotationSize{group, bytes})
+}
+
+// RegisterFileGroup registers a new logging group that appends to the file at path,
+// creating it if needed. When the file grows past DefaultRotationSize it is renamed
+// to path.1, replacing any previous path.1, and a fresh file is opened. It returns
+// ErrGroupExists, and the existing group's ID, if the group name already exists.
+func RegisterFileGroup(name, path string, on bool) (int, error) {
+	return std.RegisterFileGroup(name, path, on)
+}
+
+// RegisterGzipFileGroup registers a new logging group like RegisterFileGroup,
+// but gzip compresses the lines written to the file, so path should end in
+// ".gz". Compressed lines are written at least once a second, or as set with
+// SetFlushInterval, and on Flush, and Done completes the archive; logging after
+// Reset appends a new gzip member, which gzip readers read as part of the same
+// file. The file is rotated like RegisterFileGroup, by its compressed size.
+func RegisterGzipFileGroup(name, path string, on bool) (int, error) {
+	return std.RegisterGzipFileGroup(name, path, on)
+}
+
+// SetRotationSize sets the size in bytes at which a group registered with
+// RegisterFileGroup is rotated. Zero disables rotation. It has no effect on
+// other groups.
+func SetRotationSize(group int, bytes int64) {
+	std.SetRotationSize(group, bytes)
+}
@@ -0,0 +1,46 @@
+package trace
+
+type cmdSetSampleRate struct {
+	group int
+	n     int
+}
+
+func (c *cmdSetSampleRate) do(l *Logger) {
+	if g := l.getGroup(c.group); g != nil {
+		g.sampleRate = c.n
+		g.sampleCount = 0
+	}
+}
+
+// sampled is a helper function reporting whether a trace message is output
+// under the group's sample rate. It counts the message on the log goroutine.
+func (g *groupData) sampled() bool {
+	if g.sampleRate <= 1 {
+		return true
+	}
+
+	g.sampleCount++
+	if g.sampleCount < g.sampleRate {
+		return false
+	}
+	g.sampleCount = 0
+	return true
+}
+
+// SetSampleRate makes the group output only every nth trace message so that
+// trace can stay on in hot paths. Info and higher levels are never sampled.
+// Sampling is per group and counted by the log goroutine, and the count starts
+// over when the rate is set and when the Logger is reset. An n of 0 or 1 turns
+// sampling off, which is the default.
+func (l *Logger) SetSampleRate(group int, n int) {
+	l.enqueue(&cmdSetSampleRate{group, n})
+}
+
+// SetSampleRate makes the group output only every nth trace message so that
+// trace can stay on in hot paths. Info and higher levels are never sampled.
+// Sampling is per group and counted by the log goroutine, and the count starts
+// over when the rate is set and when the Logger is reset. An n of 0 or 1 turns
+// sampling off, which is the default.
+func SetSampleRate(group int, n int) {
+	std.SetSampleRate(group, n)
+}
@@ -0,0 +1,68 @@
+package trace
+
+// GroupLogger logs messages to a fixed group, so that the group ID does not
+// have to be passed to every call. Create one with Scoped.
+type GroupLogger struct {
+	logger *Logger
+	group  int
+}
+
+// Scoped returns a GroupLogger that logs every message to the given group.
+// A package can keep one for its group:
+//
+//	var auditLog = trace.Scoped(auditGroup)
+//
+//	auditLog.Infof("login %s", user)
+func (l *Logger) Scoped(group int) GroupLogger {
+	return GroupLogger{logger: l, group: group}
+}
+
+// Scoped returns a GroupLogger that logs every message to the given group.
+// A package can keep one for its group:
+//
+//	var auditLog = trace.Scoped(auditGroup)
+//
+//	auditLog.Infof("login %s", user)
+func Scoped(group int) GroupLogger {
+	return std.Scoped(group)
+}
+
+// Error logs a message to the group at error level. Similar to fmt.Print(...)
+func (s GroupLogger) Error(a ...interface{}) {
+	s.logger.log(s.group, LevelError, "", a...)
+}
+
+// Errorf logs a message to the group at error level. Similar to fmt.Printf(...)
+func (s GroupLogger) Errorf(format string, a ...interface{}) {
+	s.logger.log(s.group, LevelError, format, a...)
+}
+
+// Info logs a message to the group at info level. Similar to fmt.Print(...)
+func (s GroupLogger) Info(a ...interface{}) {
+	s.logger.log(s.group, LevelInfo, "", a...)
+}
+
+// Infof logs a message to the group at info level. Similar to fmt.Printf(...)
+func (s GroupLogger) Infof(format string, a ...interface{}) {
+	s.logger.log(s.group, LevelInfo, format, a...)
+}
+
+// Trace logs a message to the group at trace level. Similar to fmt.Print(...)
+func (s GroupLogger) Trace(a ...interface{}) {
+	s.logger.log(s.group, LevelTrace, "", a...)
+}
+
+// Tracef logs a message to the group at trace level. Similar to fmt.Printf(...)
+func (s GroupLogger) Tracef(format string, a ...interface{}) {
+	s.logger.log(s.group, LevelTrace, format, a...)
+}
+
+// Warn logs a message to the group at warn level. Similar to fmt.Print(...)
+func (s GroupLogger) Warn(a ...interface{}) {
+	s.logger.log(s.group, LevelWarn, "", a...)
+}
+
+// Warnf logs a message to the group at warn level. Similar to fmt.Printf(...)
+func (s GroupLogger) Warnf(format string, a ...interface{}) {
+	s.logger.log(s.group, LevelWarn, format, a...)
+}
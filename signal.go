@@ -0,0 +1,55 @@
+package trace
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+type cmdToggleTrace struct{}
+
+func (c *cmdToggleTrace) do(l *Logger) {
+	(&cmdEnabletrace{!l.allows(LevelTrace)}).do(l)
+}
+
+// HandleSignals turns trace level logging on when the toggle signal is
+// received, such as syscall.SIGUSR1, and off when it is received again, so that
+// trace can be switched on in production without a restart. Signals are only
+// handled after calling it. It returns a function that stops handling the
+// signal, which must be called before Done and may be called more than once.
+func (l *Logger) HandleSignals(toggle os.Signal) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	quit := make(chan struct{})
+	stopped := make(chan struct{})
+	signal.Notify(signals, toggle)
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-signals:
+				l.enqueue(&cmdToggleTrace{})
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(quit)
+		})
+		<-stopped
+	}
+}
+
+// HandleSignals turns trace level logging on when the toggle signal is
+// received, such as syscall.SIGUSR1, and off when it is received again, so that
+// trace can be switched on in production without a restart. Signals are only
+// handled after calling it. It returns a function that stops handling the
+// signal, which must be called before Done and may be called more than once.
+func HandleSignals(toggle os.Signal) (stop func()) {
+	return std.HandleSignals(toggle)
+}
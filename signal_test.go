@@ -0,0 +1,42 @@
+//go:build !windows && !plan9
+
+package trace
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+// waitTrace is a helper function for waiting until a signal has toggled trace
+func waitTrace(l *Logger, on bool) bool {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		l.Flush()
+		if l.IsTraceEnabled() == on {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
+
+func Test_HandleSignals(t *testing.T) {
+	var logMemFile memoryLog
+	l := New(&logMemFile)
+
+	stop := l.HandleSignals(syscall.SIGUSR1)
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	if !waitTrace(l, true) {
+		t.Error("HandleSignals failed: trace not enabled by signal")
+	}
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	if !waitTrace(l, false) {
+		t.Error("HandleSignals failed: trace not disabled by signal")
+	}
+
+	stop()
+	stop()
+	l.Done()
+}
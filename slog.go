@@ -0,0 +1,118 @@
+//go:build go1.21
+
+package trace
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+// slogHandler is a slog.Handler logging records to a group
+type slogHandler struct {
+	logger *Logger
+	group  int
+
+	// Attributes added with WithAttrs, keyed by their qualified name
+	fields Fields
+
+	// Names of the groups opened with WithGroup, joined and followed by a dot
+	prefix string
+}
+
+// slogLevel is a helper function for mapping a slog level to the nearest level at or below it
+func slogLevel(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return LevelTrace
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.logger.enabled(h.group, slogLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make(Fields, len(h.fields)+r.NumAttrs())
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(fields, h.prefix, a)
+		return true
+	})
+
+	// Like the handlers of log/slog, records without a PC have no caller
+	data := msgData{group: h.group, t: r.Time, fields: fields, id: h.logger.contextID(ctx), callerSet: true}
+	if mode := CallerMode(h.logger.callerMode.Load()); r.PC != 0 && mode != CallerNone {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		data.caller = mode.describe(frame.PC, frame.File, frame.Line)
+	}
+
+	h.logger.send(slogLevel(r.Level), data, "", r.Message)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(Fields, len(h.fields)+len(attrs))
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	for _, a := range attrs {
+		addSlogAttr(fields, h.prefix, a)
+	}
+
+	return &slogHandler{logger: h.logger, group: h.group, fields: fields, prefix: h.prefix}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger, group: h.group, fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+// addSlogAttr is a helper function for adding an attribute to fields. Groups are
+// flattened into keys joined with dots and empty attributes are skipped.
+func addSlogAttr(fields Fields, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			addSlogAttr(fields, prefix, ga)
+		}
+		return
+	}
+
+	fields[prefix+a.Key] = a.Value.Any()
+}
+
+// NewSlogHandler returns a slog.Handler writing records to the group, so that
+// code using log/slog is output like other trace messages. Debug records are
+// logged at trace level, and info, warn, and error records at the matching
+// level. Attributes become fields, with the names of groups opened by WithGroup
+// joined to their keys with dots.
+func (l *Logger) NewSlogHandler(group int) slog.Handler {
+	return &slogHandler{logger: l, group: group}
+}
+
+// NewSlogHandler returns a slog.Handler writing records to the group, so that
+// code using log/slog is output like other trace messages. Debug records are
+// logged at trace level, and info, warn, and error records at the matching
+// level. Attributes become fields, with the names of groups opened by WithGroup
+// joined to their keys with dots.
+func NewSlogHandler(group int) slog.Handler {
+	return std.NewSlogHandler(group)
+}
@@ -0,0 +1,110 @@
+//go:build go1.21
+
+package trace
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func Test_SlogHandler(t *testing.T) {
+	t.Parallel()
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	l := New(&logMemFile)
+	group := l.RegisterGroup("slog", &logMemFile, true)
+	logger := slog.New(l.NewSlogHandler(group))
+
+	logger.Debug("Test debug dropped")
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("SlogHandler failed: debug enabled while trace is off")
+	}
+	l.EnableTrace(true)
+	l.Flush()
+
+	logger.Debug("Test debug")
+	logger.Info("Test info", "user", "bob")
+	logger.With("service", "api").WithGroup("req").Warn("Test warn", slog.Int("code", 7), slog.Group("peer", "ip", "::1"))
+	logger.Error("Test error")
+
+	l.Done()
+
+	gold := []string{
+		timeFormat + ` TRACE \[slog\] Test debug\n$`,
+		timeFormat + ` INFO \[slog\] Test info user=bob\n$`,
+		timeFormat + ` WARN \[slog\] Test warn req.code=7 req.peer.ip=::1 service=api\n$`,
+		timeFormat + ` ERROR \[slog\] Test error\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SlogHandler failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SlogHandler failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SlogHandlerCaller(t *testing.T) {
+	t.Parallel()
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 2)
+
+	l := New(&logMemFile)
+	l.SetCallerMode(CallerFile)
+	handler := l.NewSlogHandler(DefaultGroupId)
+
+	slog.New(handler).Info("Test caller")
+	handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "Test no PC", 0))
+
+	l.Done()
+
+	gold := []string{
+		timeFormat + ` INFO slog_test\.go:\d+ Test caller\n$`,
+		timeFormat + ` INFO Test no PC\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SlogHandler failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SlogHandler failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SlogHandlerEnabledWhileWriting(t *testing.T) {
+	t.Parallel()
+
+	blocker := &blockingLog{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	l := New(blocker)
+	handler := l.NewSlogHandler(DefaultGroupId)
+
+	// The log goroutine holds mu while it is stuck writing this line
+	l.Info("Test blocked")
+	<-blocker.entered
+
+	result := make(chan bool, 1)
+	go func() { result <- handler.Enabled(context.Background(), slog.LevelWarn) }()
+	select {
+	case enabled := <-result:
+		if !enabled {
+			t.Error("SlogHandler failed: warn reported disabled")
+		}
+	case <-time.After(time.Second):
+		t.Error("SlogHandler failed: Enabled blocked on a slow output")
+	}
+
+	close(blocker.release)
+	l.Done()
+}
@@ -0,0 +1,46 @@
+package trace
+
+import (
+	"io"
+	"os"
+)
+
+type cmdSplitStdStreams struct {
+	on bool
+}
+
+func (c *cmdSplitStdStreams) do(l *Logger) {
+	l.splitStd = c.on
+}
+
+// outputsFor is a helper function for selecting the outputs of a message of
+// the given level to the group, applying SplitStdStreams
+func (l *Logger) outputsFor(group int, lvl Level) []io.Writer {
+	g := l.groups[group]
+	lvl = lvl.base()
+	if l.splitStd && group == DefaultGroupId && lvl >= LevelWarn && g.levelOutputs[lvl] == nil &&
+		(lvl != LevelError || g.errOutput == nil) && len(g.outputs) == 1 && unbatched(g.outputs[0]) == os.Stdout {
+		return []io.Writer{os.Stderr}
+	}
+	return g.outputsFor(lvl)
+}
+
+// SplitStdStreams turns on or off writing warn and error level messages of
+// the default group to os.Stderr, and trace and info level messages to
+// os.Stdout, as is conventional for command line tools. It only applies while
+// the default group writes to os.Stdout alone, and outputs set with
+// SetLevelOutput or SetErrorOutput take precedence. It is off by default, so
+// that only error level messages go to os.Stderr.
+func (l *Logger) SplitStdStreams(on bool) {
+	l.enqueue(&cmdSplitStdStreams{on})
+}
+
+// SplitStdStreams turns on or off writing warn and error level messages of
+// the default group to os.Stderr, and trace and info level messages to
+// os.Stdout, as is conventional for command line tools. It only applies while
+// the default group writes to os.Stdout alone, and outputs set with
+// SetLevelOutput or SetErrorOutput take precedence. It is off by default, so
+// that only error level messages go to os.Stderr.
+func SplitStdStreams(on bool) {
+	std.SplitStdStreams(on)
+}
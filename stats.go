@@ -0,0 +1,71 @@
+package trace
+
+import "sync/atomic"
+
+// Stats counts what happened to the messages of a group, as returned by GroupStats
+type Stats struct {
+	// Emitted is the number of messages written to the group's outputs
+	Emitted uint64
+
+	// Dropped is the number of messages discarded because the buffer was full
+	// while PolicyDrop was in effect. A message logged to several groups, such
+	// as with InfoGroups, is counted for each of them
+	Dropped uint64
+
+	// RateLimited is the number of messages dropped by the group's rate limit
+	RateLimited uint64
+
+	// Deduped is the number of repeated messages collapsed by SetDedup
+	Deduped uint64
+}
+
+// countDropped is a helper function for counting a message of the group
+// dropped by PolicyDrop. It runs on the calling goroutine, so the counts are
+// kept apart from the groups.
+func (l *Logger) countDropped(group int) {
+	counter, ok := l.groupDropped.Load(group)
+	if !ok {
+		counter, _ = l.groupDropped.LoadOrStore(group, new(atomic.Uint64))
+	}
+	counter.(*atomic.Uint64).Add(1)
+}
+
+type cmdGroupStats struct {
+	group int
+
+	// Result for the caller, valid once done is closed
+	stats Stats
+	done  chan struct{}
+}
+
+func (c *cmdGroupStats) do(l *Logger) {
+	defer close(c.done)
+
+	if g := l.getGroup(c.group); g != nil {
+		c.stats = g.stats
+	}
+	if counter, ok := l.groupDropped.Load(c.group); ok {
+		c.stats.Dropped = counter.(*atomic.Uint64).Load()
+	}
+}
+
+// GroupStats returns the number of messages of the group that were written,
+// dropped by PolicyDrop, rate limited, and collapsed as repeats, so that the
+// filtering settings can be checked and tuned. Messages logged before the call
+// are counted once they are processed, as the call waits for them like Flush.
+// It returns zero Stats if the group is not registered.
+func (l *Logger) GroupStats(group int) Stats {
+	c := &cmdGroupStats{group: group, done: make(chan struct{})}
+	l.enqueue(c)
+	<-c.done
+	return c.stats
+}
+
+// GroupStats returns the number of messages of the group that were written,
+// dropped by PolicyDrop, rate limited, and collapsed as repeats, so that the
+// filtering settings can be checked and tuned. Messages logged before the call
+// are counted once they are processed, as the call waits for them like Flush.
+// It returns zero Stats if the group is not registered.
+func GroupStats(group int) Stats {
+	return std.GroupStats(group)
+}
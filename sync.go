@@ -0,0 +1,51 @@
+package trace
+
+import "os"
+
+// syncer is implemented by outputs that can commit written lines to stable
+// storage, such as *os.File
+type syncer interface {
+	Sync() error
+}
+
+// syncOutput is a helper function for syncing an output. Files that are not
+// regular files, such as terminals and pipes, cannot be synced, so their
+// errors are ignored.
+func syncOutput(s syncer) error {
+	err := s.Sync()
+	if f, ok := s.(*os.File); ok && err != nil {
+		if info, serr := f.Stat(); serr == nil && !info.Mode().IsRegular() {
+			return nil
+		}
+	}
+	return err
+}
+
+type cmdSetSyncWrites struct {
+	group int
+	on    bool
+}
+
+func (c *cmdSetSyncWrites) do(l *Logger) {
+	if g := l.getGroup(c.group); g != nil {
+		g.syncWrites = c.on
+	}
+}
+
+// SetSyncWrites turns on or off syncing the group's outputs after every line
+// so that no line is lost if the machine crashes, at the cost of much slower
+// writes. Outputs without a Sync() error method, batched outputs, and files
+// that are not regular files, such as os.Stdout on a terminal, are not synced.
+// It is off by default.
+func (l *Logger) SetSyncWrites(group int, on bool) {
+	l.enqueue(&cmdSetSyncWrites{group, on})
+}
+
+// SetSyncWrites turns on or off syncing the group's outputs after every line
+// so that no line is lost if the machine crashes, at the cost of much slower
+// writes. Outputs without a Sync() error method, batched outputs, and files
+// that are not regular files, such as os.Stdout on a terminal, are not synced.
+// It is off by default.
+func SetSyncWrites(group int, on bool) {
+	std.SetSyncWrites(group, on)
+}
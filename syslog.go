@@ -0,0 +1,71 @@
+//go:build !windows && !plan9
+
+package trace
+
+import "log/syslog"
+
+// syslogWriter writes lines to the local syslog daemon with the priority of their level
+type syslogWriter struct {
+	w *syslog.Writer
+}
+
+func (s *syslogWriter) Write(p []byte) (n int, err error) {
+	return s.writeLevel(LevelInfo, p)
+}
+
+func (s *syslogWriter) writeLevel(lvl Level, p []byte) (n int, err error) {
+	msg := string(p)
+	switch lvl {
+	case LevelTrace:
+		err = s.w.Debug(msg)
+	case LevelWarn:
+		err = s.w.Warning(msg)
+	case LevelError:
+		err = s.w.Err(msg)
+	default:
+		err = s.w.Info(msg)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *syslogWriter) Close() error {
+	return s.w.Close()
+}
+
+// RegisterSyslogGroup registers a new logging group that writes to the local
+// syslog daemon with the given tag. Levels are mapped to syslog priorities:
+// trace to DEBUG, info to INFO, warn to WARNING, and error to ERR. Syslog adds its
+// own timestamp, so consider SetTimestamps(false). It returns ErrGroupExists, and
+// the existing group's ID, if the group name already exists, and
+// ErrSyslogUnsupported on Windows and Plan 9.
+func (l *Logger) RegisterSyslogGroup(name, tag string, on bool) (int, error) {
+	if id, ok := l.GroupByName(name); ok {
+		return id, ErrGroupExists
+	}
+
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return 0, err
+	}
+
+	s := &syslogWriter{w}
+	group, err := l.RegisterGroupE(name, s, on)
+	if err != nil {
+		s.Close()
+	}
+	return group, err
+}
+
+// RegisterSyslogGroup registers a new logging group that writes to the local
+// syslog daemon with the given tag. Levels are mapped to syslog priorities:
+// trace to DEBUG, info to INFO, warn to WARNING, and error to ERR. Syslog adds its
+// own timestamp, so consider SetTimestamps(false). It returns ErrGroupExists, and
+// the existing group's ID, if the group name already exists, and
+// ErrSyslogUnsupported on Windows and Plan 9.
+func RegisterSyslogGroup(name, tag string, on bool) (int, error) {
+	return std.RegisterSyslogGroup(name, tag, on)
+}
@@ -0,0 +1,15 @@
+//go:build windows || plan9
+
+package trace
+
+// RegisterSyslogGroup returns ErrSyslogUnsupported because there is no syslog
+// on this platform.
+func (l *Logger) RegisterSyslogGroup(name, tag string, on bool) (int, error) {
+	return 0, ErrSyslogUnsupported
+}
+
+// RegisterSyslogGroup returns ErrSyslogUnsupported because there is no syslog
+// on this platform.
+func RegisterSyslogGroup(name, tag string, on bool) (int, error) {
+	return std.RegisterSyslogGroup(name, tag, on)
+}
@@ -0,0 +1,52 @@
+package trace
+
+import "time"
+
+// Timer measures how long an operation takes and logs it when stopped.
+// Create one with StartTimer.
+type Timer struct {
+	logger *Logger
+	group  int
+	name   string
+	start  time.Time
+}
+
+// StartTimer returns a Timer for the operation name that started now. Stop
+// logs its duration to the group at info level:
+//
+//	defer trace.StartTimer(group, "query").Stop()
+func (l *Logger) StartTimer(group int, name string) Timer {
+	return Timer{logger: l, group: group, name: name, start: l.now()}
+}
+
+// StartTimer returns a Timer for the operation name that started now. Stop
+// logs its duration to the group at info level:
+//
+//	defer trace.StartTimer(group, "query").Stop()
+func StartTimer(group int, name string) Timer {
+	return std.StartTimer(group, name)
+}
+
+// Stop logs the time elapsed since StartTimer at info level, like
+// "query took 1.2ms", and returns it
+func (t Timer) Stop() time.Duration {
+	elapsed := t.logger.now().Sub(t.start)
+	t.logger.log(t.group, LevelInfo, "%s took %v", t.name, roundDuration(elapsed))
+	return elapsed
+}
+
+// roundDuration is a helper function for rounding a duration to one decimal
+// of its largest unit, so that 1.234567ms is written as 1.2ms
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Minute:
+		return d.Round(time.Second)
+	case d >= time.Second:
+		return d.Round(100 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond)
+	case d >= time.Microsecond:
+		return d.Round(100 * time.Nanosecond)
+	}
+	return d
+}
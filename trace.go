@@ -1,11 +1,18 @@
 // Package trace provides efficent and minimalist logging.
 //
-// Two logging levels are defined: trace and info. The trace level
+// Four logging levels are defined: trace, info, warn, and error. The trace level
 // is for developers who debug code. The info level is for
 // software operators (the folks running the code.) Examples of events
 // the info level could include are logins, webpage loads,
 // requests, hardware failures, or error events that cannot be
-// handled gracefully.
+// handled gracefully. The warn level sits above info and is for
+// recoverable but notable conditions such as retries or slow queries.
+// The error level is for failures. Like info, the warn and error levels
+// are always on. Error level messages of the default group are written
+// to os.Stderr so they can be captured separately from other output.
+//
+// Each line is prefixed with the name of its level (TRACE, INFO, WARN, or ERROR)
+// so that log files can be filtered by severity.
 //
 // Logging groups are provided for organizing certain types of
 // events and differientating their output location. For
@@ -19,13 +26,41 @@
 // level should typically be off in production systems. Groups and
 // the trace level can be turned on or off while the software is running.
 // The trace level is disabled by default.
+//
+// Lines are written as human readable text by default. SetFormat switches
+// the output to JSON or logfmt lines for log aggregators, and WithFields
+// attaches key/value fields to messages. Messages can also carry a request or
+// correlation ID, given directly to the ID functions such as InfofID or
+// taken from a context by the Ctx functions, so that the lines of one
+// request can be found among concurrent output.
+//
+// Messages are formatted on the log goroutine, and only when they are
+// actually output, so suppressed logs cost little more than queueing.
+// As a consequence arguments must not be modified after a logging call
+// returns: pass copies of slices, maps, and structs referenced by pointer
+// that the caller keeps changing.
+//
+// All functions are safe for concurrent use. Log messages and configuration
+// changes are queued on a channel and applied in order by a single log
+// goroutine, which owns the package state and writes to the outputs, so
+// writers never see concurrent calls. Functions returning a result, such as
+// RegisterGroup, wait for the log goroutine to reply. The few functions that
+// only read the groups, such as ListGroups, hold a mutex that the log
+// goroutine also holds while it works. Call Done before the program exits
+// so that queued messages are written, or Close to also close the outputs.
+//
+// The package level functions use a default Logger writing to os.Stdout.
+// New creates independent Loggers, each with its own groups, settings, and
+// log goroutine, for libraries that must not change the program's logging
+// or for tests that run in parallel.
 package trace
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"sync"
+	"strconv"
 	"time"
 )
 
@@ -33,221 +68,572 @@ const (
 	// DefaultGroupId is the ID of the default logging group
 	DefaultGroupId = 0
 
-	// Number of logging requests and commands the channel buffer can hold
+	// Default number of logging requests and commands the channel buffer can hold
 	chanBufSize = 1024
+
+	// Number of stack frames between send and the caller of a logging function
+	callerSkip = 3
+)
+
+// FatalExitCode is the status code the Fatal functions exit the program with
+var FatalExitCode = 1
+
+// Exits the program. Replaced by tests
+var osExit = os.Exit
+
+var (
+	// ErrGroupExists is returned by RegisterGroupE when the group name is already registered
+	ErrGroupExists = errors.New("trace: group name already exists")
+
+	// ErrUnknownGroup is the panic value of SetGroupOutput when the group is not registered
+	ErrUnknownGroup = errors.New("trace: group is not registered")
+
+	// ErrStreamActive is returned by SetBufferSize when messages have already been logged
+	ErrStreamActive = errors.New("trace: log stream is active")
+
+	// ErrBufferSize is returned by SetBufferSize when the size is not positive
+	ErrBufferSize = errors.New("trace: buffer size must be positive")
+
+	// ErrDoneTimeout is returned by DoneTimeout when queued logs were not output in time
+	ErrDoneTimeout = errors.New("trace: timed out waiting for logs to be output")
+
+	// ErrLevelExists is the panic value of RegisterLevel when the level name is already used
+	ErrLevelExists = errors.New("trace: level name already exists")
+
+	// ErrSyslogUnsupported is returned by RegisterSyslogGroup on platforms without syslog
+	ErrSyslogUnsupported = errors.New("trace: syslog is not supported on this platform")
 )
 
-type level int
+// OverflowPolicy selects what happens to a log message when the buffer is full
+type OverflowPolicy int32
 
 const (
-	// Logging level for what developers care about
-	trace level = iota + 1
+	// PolicyBlock blocks the logging goroutine until there is room. This is the default
+	PolicyBlock OverflowPolicy = iota
 
-	// Logging level for what software operators care about
-	info
+	// PolicyDrop discards the message and counts it. See DroppedCount
+	PolicyDrop
 )
 
-var (
-	// Channel for ordering and concurrently outputing log messages
-	logstream chan logApi
+// Level is the severity of a log message. Levels are ordered so that
+// comparisons like l >= LevelInfo work for filtering.
+type Level int
+
+const (
+	// LevelTrace is the logging level for what developers care about
+	LevelTrace Level = iota + 1
 
-	// Tracks when logRoutine has completed all requests
-	waitGroup sync.WaitGroup
+	// LevelInfo is the logging level for what software operators care about
+	LevelInfo
 
-	// Keeps all logging groups. Default group has index = 0 and name = ""
-	groups []*groupData = make([]*groupData, 0, 4)
+	// LevelWarn is the logging level for recoverable conditions operators should notice
+	LevelWarn
 
-	// Indicates whether to output trace level logs
-	traceEnabled bool = false
+	// LevelError is the logging level for failures
+	LevelError
 )
 
-func init() {
-	reset()
+// Names of the logging levels as they appear in the output
+var levelNames = [...]string{
+	LevelTrace: "TRACE",
+	LevelInfo:  "INFO",
+	LevelWarn:  "WARN",
+	LevelError: "ERROR",
 }
 
-type groupData struct {
-	name    string
-	output  io.Writer
-	enabled bool
+// String returns the name of the level as it appears in FormatText lines,
+// such as "INFO" or a name given to RegisterLevel, or "Level(n)" for values
+// that are not a level.
+func (l Level) String() string {
+	if l >= LevelTrace && l <= LevelError {
+		return levelNames[l]
+	}
+	if c, ok := lookupCustomLevel(l); ok {
+		return c.name
+	}
+	return "Level(" + strconv.Itoa(int(l)) + ")"
 }
 
-type logApi interface {
-	do()
+// GroupInfo describes a registered logging group
+type GroupInfo struct {
+	ID      int
+	Name    string
+	Enabled bool
 }
 
-type traceMsg struct {
-	group int
-	t     time.Time
-	msg   string
+// The default Logger used by the package level functions
+var std = New(os.Stdout)
+
+// AddGroupOutput adds another output location to the group so that its logs
+// are written to every output in turn, for example to both a file and os.Stdout.
+// If one output fails the others are still written. Error level messages keep
+// going to the error output if one is set (see SetErrorOutput).
+func AddGroupOutput(group int, output io.Writer) {
+	std.AddGroupOutput(group, output)
 }
 
-func (m *traceMsg) do() {
-	if traceEnabled && groups[m.group].enabled {
-		printLog(m.group, m.t, m.msg)
-	}
+// ClearGroups removes all groups but the default group, returning the Logger
+// to its initial set of groups without restarting it, for example between
+// tests. Messages queued for the removed groups are output first, and their
+// outputs are then closed unless the default group also writes to them. Group
+// IDs are reused afterwards, so IDs of removed groups must not be used again.
+func ClearGroups() {
+	std.ClearGroups()
 }
 
-type infoMsg struct {
-	group int
-	t     time.Time
-	msg   string
+// Close is called at end of program instead of Done to output all queued logs
+// and then close every group output that implements io.Closer, such as files
+// opened by RegisterFileGroup. os.Stdout and os.Stderr are never closed.
+//
+// Done leaves the outputs open because they are owned by the caller. Use Close
+// when the outputs were handed over to the package, so that files are flushed
+// and released without keeping a reference to them.
+func Close() {
+	std.Close()
 }
 
-func (m *infoMsg) do() {
-	if groups[m.group].enabled {
-		printLog(m.group, m.t, m.msg)
-	}
+// Done is called at end of program to ensure all logs are printed. Calling it
+// again has no effect until the package is restarted with Reset. Messages logged
+// afterwards, for example by background goroutines during shutdown, are
+// dropped, while other calls such as EnableTrace or RegisterGroup take effect
+// on the calling goroutine. Output halted by Pause is resumed.
+func Done() {
+	std.Done()
 }
 
-type cmdEnabletrace struct {
-	on bool
+// DoneTimeout is called at end of program like Done, but waits at most d for
+// queued logs to be output, so that a hanging writer such as a stuck network
+// connection cannot keep the program from exiting. It returns ErrDoneTimeout if
+// the logs were not all output in time; the log goroutine then keeps writing
+// in the background.
+func DoneTimeout(d time.Duration) error {
+	return std.DoneTimeout(d)
 }
 
-func (c *cmdEnabletrace) do() {
-	traceEnabled = c.on
+// DroppedCount returns the number of log messages discarded because the buffer
+// was full while PolicyDrop was in effect.
+func DroppedCount() uint64 {
+	return std.DroppedCount()
 }
 
-type cmdEnableGroup struct {
-	group int
-	on    bool
+// EnableCaller turns on or off capturing the source file and line of every log
+// call, such as "server.go:42". It is off by default because capturing the
+// caller has a measurable cost on the calling goroutine. The change applies to
+// log calls made after EnableCaller returns. It is equivalent to SetCallerMode
+// with CallerFile or CallerNone.
+func EnableCaller(on bool) {
+	std.EnableCaller(on)
 }
 
-func (c *cmdEnableGroup) do() {
-	groups[c.group].enabled = c.on
+// EnableDefault turns the default group logging on or off. It is equivalent
+// to EnableGroup(DefaultGroupId, on), and can be called at startup, before
+// anything is logged, to silence the default group.
+func EnableDefault(on bool) {
+	std.EnableDefault(on)
 }
 
-// log is a helper function for processing new log requests from the caller
-func log(group int, l level, format string, a ...interface{}) {
-	t := time.Now()
+// EnableGroup turns the group logging on or off
+func EnableGroup(group int, on bool) {
+	std.EnableGroup(group, on)
+}
 
-	var m string
-	if len(format) > 0 {
-		m = fmt.Sprintf(format, a...)
-	} else {
-		m = fmt.Sprint(a...)
-	}
+// EnableGroupByPrefix turns on or off every group whose name starts with
+// prefix, so that dotted names form a hierarchy: EnableGroupByPrefix("db", false)
+// turns off "db", "db.query" and "db.tx". Pass "db." to leave "db" itself
+// alone. Groups registered later are not affected.
+func EnableGroupByPrefix(prefix string, on bool) {
+	std.EnableGroupByPrefix(prefix, on)
+}
 
-	var cmd logApi
-	if l == trace {
-		cmd = &traceMsg{group: group, t: t, msg: m}
-	} else if l == info {
-		cmd = &infoMsg{group: group, t: t, msg: m}
-	}
+// EnableSequence turns on or off numbering every log message, written like
+// #000123, in the order the logging calls were made. Output of concurrent
+// goroutines can then be put back in call order. Messages dropped by
+// PolicyDrop still take a number, so gaps show where they were. It is off
+// by default. The change applies to log calls made after EnableSequence returns.
+func EnableSequence(on bool) {
+	std.EnableSequence(on)
+}
 
-	logstream <- cmd
+// EnableTrace turns tracing level logging on or off. It is kept for
+// compatibility with SetMinLevel: turning trace on lowers the minimum level to
+// LevelTrace, and turning it off raises it to LevelInfo if it was lower.
+func EnableTrace(on bool) {
+	std.EnableTrace(on)
 }
 
-// logRoutine is a goroutine for outputing logging in parallel
-func logRoutine() {
-	for i := range logstream {
-		i.do()
-	}
+// Error logs a message to default group at error level. Similar to fmt.Print(...)
+func Error(a ...interface{}) {
+	std.log(0, LevelError, "", a...)
+}
 
-	waitGroup.Done()
+// Errorf logs a message to default group at error level. Similar to fmt.Printf(...)
+func Errorf(format string, a ...interface{}) {
+	std.log(0, LevelError, format, a...)
 }
 
-// printLog is a helper function for formating a log message
-func printLog(group int, t time.Time, msg string) {
-	strTime := t.UTC().Format("2006-1-2 15:04:05.000000")
-	if group == DefaultGroupId {
-		fmt.Fprintf(groups[DefaultGroupId].output, "%s %s\n", strTime, msg)
-	} else {
-		groupname := groups[group].name
-		fmt.Fprintf(groups[group].output, "%s [%s] %s\n", strTime, groupname, msg)
-	}
+// Errorg logs a message to given group at error level. Similar to fmt.Print(...)
+func Errorg(group int, a ...interface{}) {
+	std.log(group, LevelError, "", a...)
 }
 
-// reset is a helper function for initializing the trace package.
-func reset() {
-	if len(groups) == 0 {
-		groups = append(groups, &groupData{name: "", output: os.Stdout, enabled: true})
-	}
+// Errorgf logs a message to given group at error level. Similar to fmt.Printf(...)
+func Errorgf(group int, format string, a ...interface{}) {
+	std.log(group, LevelError, format, a...)
+}
 
-	logstream = make(chan logApi, chanBufSize)
-	waitGroup.Add(1)
-	go logRoutine()
+// Fatal logs a message to default group at error level, flushes all queued logs,
+// and exits the program with FatalExitCode. Similar to fmt.Print(...)
+//
+// Unlike the other logging functions, Fatal blocks until the output is written.
+func Fatal(a ...interface{}) {
+	std.log(0, LevelError, "", a...)
+	std.Flush()
+	osExit(FatalExitCode)
 }
 
-// Done is called at end of program to ensure all logs are printed
-func Done() {
-	close(logstream)
-	waitGroup.Wait()
+// Fatalf logs a message to default group at error level, flushes all queued logs,
+// and exits the program with FatalExitCode. Similar to fmt.Printf(...)
+func Fatalf(format string, a ...interface{}) {
+	std.log(0, LevelError, format, a...)
+	std.Flush()
+	osExit(FatalExitCode)
 }
 
-// EnableGroup turns the group logging on or off
-func EnableGroup(group int, on bool) {
-	logstream <- &cmdEnableGroup{group, on}
+// Fatalg logs a message to given group at error level, flushes all queued logs,
+// and exits the program with FatalExitCode. Similar to fmt.Print(...)
+func Fatalg(group int, a ...interface{}) {
+	std.log(group, LevelError, "", a...)
+	std.Flush()
+	osExit(FatalExitCode)
 }
 
-// EnableTrace turns tracing level logging on or off
-func EnableTrace(on bool) {
-	logstream <- &cmdEnabletrace{on}
+// Fatalgf logs a message to given group at error level, flushes all queued logs,
+// and exits the program with FatalExitCode. Similar to fmt.Printf(...)
+func Fatalgf(group int, format string, a ...interface{}) {
+	std.log(group, LevelError, format, a...)
+	std.Flush()
+	osExit(FatalExitCode)
+}
+
+// Flush blocks until all logs queued before the call have been output. Unlike
+// Done, logging can continue afterwards. Call it before os.Exit or in crash
+// handlers to make sure pending logs are not lost.
+func Flush() {
+	std.Flush()
+}
+
+// GroupByName returns the ID of the group registered with the given name and
+// whether it was found. It lets packages share a group registered elsewhere
+// without passing its ID around. The default group has the empty name.
+func GroupByName(name string) (int, bool) {
+	return std.GroupByName(name)
+}
+
+// GroupWriterOf returns the output the group writes to, as set by RegisterGroup,
+// SetGroupOutput, or SetDefaultGroup, so that it can be wrapped and set again.
+// Outputs added with AddGroupOutput are not returned. It returns nil if the group
+// is not registered. The package keeps writing to the output on the log
+// goroutine, so writing to it directly may interleave with log lines, and it
+// must not be closed while the group uses it.
+func GroupWriterOf(group int) io.Writer {
+	return std.GroupWriterOf(group)
 }
 
 // Info logs a message to default group at info level. Similar to fmt.Print(...)
 func Info(a ...interface{}) {
-	log(0, info, "", a...)
+	std.log(0, LevelInfo, "", a...)
 }
 
 // Infof logs a message to default group at info level. Similar to fmt.Printf(...)
 func Infof(format string, a ...interface{}) {
-	log(0, info, format, a...)
+	std.log(0, LevelInfo, format, a...)
 }
 
 // Infog logs a message to given group at info level. Similar to fmt.Print(...)
 func Infog(group int, a ...interface{}) {
-	log(group, info, "", a...)
+	std.log(group, LevelInfo, "", a...)
 }
 
 // Infogf logs a message to given group. Similar to fmt.Printf(...)
 func Infogf(group int, format string, a ...interface{}) {
-	log(group, info, format, a...)
+	std.log(group, LevelInfo, format, a...)
+}
+
+// IsGroupEnabled reports whether the group is registered and turned on. Like
+// IsTraceEnabled it reflects EnableGroup calls already processed by the log goroutine.
+func IsGroupEnabled(group int) bool {
+	return std.IsGroupEnabled(group)
+}
+
+// IsTraceEnabled reports whether trace level logging is on. Use it to skip
+// building expensive trace messages:
+//
+//	if trace.IsTraceEnabled() {
+//		trace.Tracef("state: %s", dump())
+//	}
+//
+// EnableTrace is applied by the log goroutine in order with queued logs, so a
+// change only shows once it has been processed. Call Flush first to be certain.
+func IsTraceEnabled() bool {
+	return std.IsTraceEnabled()
+}
+
+// ListGroups returns a snapshot of the registered logging groups, including
+// the default group, ordered by ID. It is safe to call concurrently with logging
+// and with RegisterGroup.
+func ListGroups() []GroupInfo {
+	return std.ListGroups()
+}
+
+// Panic logs a message to default group at error level, flushes all queued logs,
+// and panics with the message. Similar to fmt.Print(...)
+//
+// The formatted message is both logged and passed to panic. Unlike the other
+// logging functions, Panic blocks until the output is written so the message
+// is not lost while the stack unwinds.
+func Panic(a ...interface{}) {
+	msg := fmt.Sprint(a...)
+	std.log(0, LevelError, "", msg)
+	std.Flush()
+	panic(msg)
+}
+
+// Panicf logs a message to default group at error level, flushes all queued logs,
+// and panics with the message. Similar to fmt.Printf(...)
+func Panicf(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	std.log(0, LevelError, "", msg)
+	std.Flush()
+	panic(msg)
+}
+
+// Panicg logs a message to given group at error level, flushes all queued logs,
+// and panics with the message. Similar to fmt.Print(...)
+func Panicg(group int, a ...interface{}) {
+	msg := fmt.Sprint(a...)
+	std.log(group, LevelError, "", msg)
+	std.Flush()
+	panic(msg)
+}
+
+// Panicgf logs a message to given group at error level, flushes all queued logs,
+// and panics with the message. Similar to fmt.Printf(...)
+func Panicgf(group int, format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	std.log(group, LevelError, "", msg)
+	std.Flush()
+	panic(msg)
 }
 
 // RegisterGroup registers a new logging group.
 //
 // It is to be called in a package's init() function. It returns a unique group ID
 // for the calling package to store so it can later change the group configuration.
+// It panics if the group name already exists. See RegisterGroupE.
 func RegisterGroup(name string, output io.Writer, on bool) int {
-	for _, group := range groups {
-		if name == group.name {
-			panic("Group name already exists")
-		}
-	}
+	return std.RegisterGroup(name, output, on)
+}
 
-	if len(groups) == 0 {
-		groups = append(groups, &groupData{name: "", output: os.Stdout, enabled: true})
-	}
+// RegisterGroupE registers a new logging group like RegisterGroup, but returns
+// ErrGroupExists instead of panicking if the group name already exists. In that
+// case the returned ID is that of the existing group so the caller can reuse it.
+func RegisterGroupE(name string, output io.Writer, on bool) (int, error) {
+	return std.RegisterGroupE(name, output, on)
+}
+
+// Reset restarts the Logger after Done, so that test suites calling Done in
+// every test can log again. If the Logger is still running it is first drained
+// like Done, so no goroutine is left behind. Settings and registered groups are
+// kept unless clearGroups is set, in which case only the default group remains
+// and the outputs of the removed groups are closed. Group IDs are then reused.
+func Reset(clearGroups bool) {
+	std.Reset(clearGroups)
+}
 
-	groups = append(groups, &groupData{name: name, output: output, enabled: on})
-	return len(groups) - 1
+// SetBufferSize sets the number of log messages and commands that can be queued
+// before logging blocks or drops messages (see SetOverflowPolicy). The default is 1024.
+//
+// The stream is recreated with the new size, so SetBufferSize must be called
+// before the first log and before other goroutines use the package, typically
+// at the start of main. Commands queued earlier, such as EnableTrace, are kept.
+// It returns ErrStreamActive if messages have already been logged; to change the
+// size after logging has started, call Done before restarting the stream.
+func SetBufferSize(n int) error {
+	return std.SetBufferSize(n)
+}
+
+// SetDefaultGroup sets the output location of the default logging group,
+// replacing any outputs added with AddGroupOutput. Like SetGroupOutput the
+// change is applied between two lines.
+//
+// Error level messages of the default group are written to os.Stderr unless
+// changed with SetErrorOutput.
+func SetDefaultGroup(output io.Writer) {
+	std.SetDefaultGroup(output)
 }
 
-// SetDefaultOutput sets the output location of for the default logging group.
+// SetDefaultOutput is an alias of SetDefaultGroup kept for backward compatibility.
+//
+// Deprecated: Use SetDefaultGroup.
 func SetDefaultOutput(output io.Writer) {
-	if len(groups) == 0 {
-		groups = append(groups, &groupData{name: "", output: output, enabled: true})
-	} else {
-		groups[0] = &groupData{name: "", output: output, enabled: groups[0].enabled}
-	}
+	std.SetDefaultGroup(output)
+}
+
+// SetEnabled turns all logging on or off. While off, logging calls return
+// immediately without reading the clock, formatting, or queueing, so it is the
+// cheapest way to silence the package, for example in benchmarks. It is
+// independent of EnableGroup and EnableTrace. Logging is on by default.
+func SetEnabled(on bool) {
+	std.SetEnabled(on)
+}
+
+// SetErrorHandler sets the function called when writing a log line to one of a
+// group's outputs fails. By default failures are reported on os.Stderr. A nil
+// handler restores the default.
+//
+// The handler runs on the log goroutine, so it must not block and must not log
+// through this package, which would deadlock once the buffer is full.
+func SetErrorHandler(handler func(group int, err error)) {
+	std.SetErrorHandler(handler)
+}
+
+// SetErrorOutput sets the output location for error level messages of the given
+// group. By default the default group writes errors to os.Stderr and all other
+// groups write errors to their regular output. Passing nil makes the group write
+// errors to its regular output.
+func SetErrorOutput(group int, output io.Writer) {
+	std.SetErrorOutput(group, output)
+}
+
+// SetGroupOutput sets the output location of the group, replacing any outputs
+// added with AddGroupOutput, for example to switch a group to a new file after
+// rotating it externally. Like all changes it is applied by the log goroutine
+// between two lines, so a line is never split between the old and the new
+// output, and the old output can be closed once Flush returns. The old output
+// is not closed. It panics with ErrUnknownGroup if the group was never
+// registered or has been unregistered, and if output is nil.
+func SetGroupOutput(group int, output io.Writer) {
+	std.SetGroupOutput(group, output)
+}
+
+// SetGroupLevel sets the minimum level the group outputs. Messages below min
+// are dropped, for example SetGroupLevel(audit, LevelInfo) drops trace messages
+// of the audit group. Trace messages additionally require EnableTrace. Groups
+// output all levels by default.
+func SetGroupLevel(group int, min Level) {
+	std.SetGroupLevel(group, min)
+}
+
+// SetGroupPrefix sets a static prefix, such as a service or host name, written
+// after the timestamp of every line of the group. With FormatJSON and
+// FormatLogfmt it is written as the "prefix" key. An empty prefix removes it.
+func SetGroupPrefix(group int, prefix string) {
+	std.SetGroupPrefix(group, prefix)
+}
+
+// SetLevelOutput sets the output location for messages of the given level of
+// the group, so that one group can for example write trace messages to a debug
+// file and the other levels to its main output. It takes precedence over the
+// group's outputs and SetErrorOutput. Passing nil restores them for the level.
+func SetLevelOutput(group int, level Level, output io.Writer) {
+	std.SetLevelOutput(group, level, output)
+}
+
+// SetMinLevel sets the minimum level output by all groups, for example
+// SetMinLevel(LevelWarn) drops trace and info messages everywhere. Groups can
+// raise it further with SetGroupLevel. Setting it to LevelTrace turns trace on
+// like EnableTrace(true), and any higher level turns trace off. The default is
+// LevelInfo. Like EnableTrace it applies in order with queued logs.
+func SetMinLevel(min Level) {
+	std.SetMinLevel(min)
+}
+
+// SetOverflowPolicy sets what happens to a log message when the buffer is full.
+// By default the logging call blocks until there is room. With PolicyDrop the
+// message is discarded and counted instead so callers never stall. Commands such
+// as EnableTrace are never dropped.
+func SetOverflowPolicy(policy OverflowPolicy) {
+	std.SetOverflowPolicy(policy)
+}
+
+// SetSynchronous turns synchronous mode on or off. In synchronous mode log
+// messages and commands are output on the calling goroutine before the call
+// returns, instead of being queued, which makes tests deterministic without
+// calling Done. Turning it on first flushes messages already queued so ordering
+// is kept. It is off by default because logging calls then wait on the writers.
+func SetSynchronous(on bool) {
+	std.SetSynchronous(on)
+}
+
+// SetThreshold is SetMinLevel under the name used by other logging packages: it
+// sets the level below which messages of every group are dropped.
+// EnableTrace(true) is shorthand for SetThreshold(LevelTrace).
+func SetThreshold(min Level) {
+	std.SetMinLevel(min)
 }
 
 // Trace logs a message to default group at trace level. Similar to fmt.Print(...)
 func Trace(a ...interface{}) {
-	log(0, trace, "", a...)
+	std.log(0, LevelTrace, "", a...)
 }
 
 // Trace logs a message to default group at trace level. Similar to fmt.Printf(...)
 func Tracef(format string, a ...interface{}) {
-	log(0, trace, format, a...)
+	std.log(0, LevelTrace, format, a...)
 }
 
 // Traceg logs a message to given group at trace level. Similar to fmt.Print(...)
 func Traceg(group int, a ...interface{}) {
-	log(group, trace, "", a...)
+	std.log(group, LevelTrace, "", a...)
 }
 
 // Tracegf logs a message to given group at trace level. Similar to fmt.Printf(...)
 func Tracegf(group int, format string, a ...interface{}) {
-	log(group, trace, format, a...)
+	std.log(group, LevelTrace, format, a...)
+}
+
+// TraceFunc logs the message returned by f to given group at trace level. f is
+// only called, on the calling goroutine, if trace and the group are enabled, so
+// expensive debug strings are not built while they would be dropped. Like
+// IsTraceEnabled it sees changes already processed by the log goroutine.
+func TraceFunc(group int, f func() string) {
+	if std.enabled(group, LevelTrace) {
+		std.log(group, LevelTrace, "", f())
+	}
+}
+
+// UnregisterGroup removes a logging group. If the group's writers implement
+// io.Closer they are closed, except for os.Stdout and os.Stderr. Logs queued
+// before the call are still output; later logs to the group are dropped.
+// Group IDs are not reused and the default group cannot be removed.
+func UnregisterGroup(group int) {
+	std.UnregisterGroup(group)
+}
+
+// Warn logs a message to default group at warn level. Similar to fmt.Print(...)
+func Warn(a ...interface{}) {
+	std.log(0, LevelWarn, "", a...)
+}
+
+// Warnf logs a message to default group at warn level. Similar to fmt.Printf(...)
+func Warnf(format string, a ...interface{}) {
+	std.log(0, LevelWarn, format, a...)
+}
+
+// Warng logs a message to given group at warn level. Similar to fmt.Print(...)
+func Warng(group int, a ...interface{}) {
+	std.log(group, LevelWarn, "", a...)
+}
+
+// Warngf logs a message to given group at warn level. Similar to fmt.Printf(...)
+func Warngf(group int, format string, a ...interface{}) {
+	std.log(group, LevelWarn, format, a...)
+}
+
+// WithTrace turns trace level logging on, runs f, and then restores the
+// previous minimum level, even if f panics, so that trace is only output for
+// that code path. Logs queued before the call are output with the previous
+// level, and the change is visible to other goroutines logging while f runs.
+func WithTrace(f func()) {
+	std.WithTrace(f)
 }
@@ -1,8 +1,19 @@
 package trace
 
 import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 const (
@@ -17,11 +28,96 @@ func (l *memoryLog) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// implements io.Writer, failing while failures is positive and counting it down
+type flakyLog struct {
+	memoryLog
+	failures int
+}
+
+func (l *flakyLog) Write(p []byte) (n int, err error) {
+	if l.failures > 0 {
+		l.failures--
+		return 0, errors.New("write failed")
+	}
+	return l.memoryLog.Write(p)
+}
+
+// implements io.Writer, always failing
+type failingLog struct{}
+
+func (l failingLog) Write(p []byte) (n int, err error) {
+	return 0, errors.New("write failed")
+}
+
+// implements io.WriteCloser, recording whether it was closed
+type closingLog struct {
+	memoryLog
+	closed bool
+}
+
+func (l *closingLog) Close() error {
+	l.closed = true
+	return nil
+}
+
+// implements io.Writer and levelWriter, recording the level of each line
+type levelLog struct {
+	memoryLog
+	levels []Level
+}
+
+func (l *levelLog) writeLevel(lvl Level, p []byte) (n int, err error) {
+	l.levels = append(l.levels, lvl)
+	return l.Write(p)
+}
+
+// implements io.Writer and Sync, counting the syncs
+type syncingLog struct {
+	memoryLog
+	syncs int
+}
+
+func (l *syncingLog) Sync() error {
+	l.syncs++
+	return nil
+}
+
+// implements io.Writer and flusher, counting the flushes
+type flushingLog struct {
+	memoryLog
+	flushes int
+}
+
+func (l *flushingLog) flush(final bool) error {
+	l.flushes++
+	return nil
+}
+
+// implements io.Writer, blocking the log goroutine until released.
+// entered must be buffered so writes after the first do not block on it.
+type blockingLog struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (l *blockingLog) Write(p []byte) (n int, err error) {
+	select {
+	case l.entered <- struct{}{}:
+	default:
+	}
+	<-l.release
+	return len(p), nil
+}
+
 func Test_Log(t *testing.T) {
+	// Starts every run of the tests from a fresh package, also after Done
+	std.Reset(true)
+
 	var logMemFile memoryLog
 	logMemFile = make([]string, 0, 4)
 
 	SetDefaultGroup(&logMemFile)
+	EnableTrace(true)
 
 	Trace("Test trace")
 	Info("Test info")
@@ -33,14 +129,15 @@ func Test_Log(t *testing.T) {
 	msgNumber = 4
 	Infof("Test info number %d", msgNumber)
 
+	EnableTrace(false)
 	Done()
 
 	var gold []string
 	gold = make([]string, 0, 4)
-	gold = append(gold, timeFormat+` Test trace`)
-	gold = append(gold, timeFormat+` Test info`)
-	gold = append(gold, timeFormat+` Test trace number 3 after others`)
-	gold = append(gold, timeFormat+` Test info number 4`)
+	gold = append(gold, timeFormat+` TRACE Test trace`)
+	gold = append(gold, timeFormat+` INFO Test info`)
+	gold = append(gold, timeFormat+` TRACE Test trace number 3 after others`)
+	gold = append(gold, timeFormat+` INFO Test info number 4`)
 
 	for i, line := range logMemFile {
 		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
@@ -50,12 +147,13 @@ func Test_Log(t *testing.T) {
 }
 
 func Test_LogGroup(t *testing.T) {
-	reset()
+	std.Reset(true)
 
 	var logMemFile memoryLog
 	logMemFile = make([]string, 0, 4)
 
 	group := RegisterGroup("test", &logMemFile, true)
+	EnableTrace(true)
 
 	Traceg(group, "Test trace")
 
@@ -68,14 +166,15 @@ func Test_LogGroup(t *testing.T) {
 	msgNumber = 4
 	Infogf(group, "Test info number %d", msgNumber)
 
+	EnableTrace(false)
 	Done()
 
 	var gold []string
 	gold = make([]string, 0, 4)
-	gold = append(gold, `\d{4}-\d{1,2}-\d{1,2} \d{1,2}:\d\d:\d\d\.\d{6} \[test\] Test trace`)
-	gold = append(gold, `\d{4}-\d{1,2}-\d{1,2} \d{1,2}:\d\d:\d\d\.\d{6} \[test\] Test info`)
-	gold = append(gold, `\d{4}-\d{1,2}-\d{1,2} \d{1,2}:\d\d:\d\d\.\d{6} \[test\] Test trace number 3 after others`)
-	gold = append(gold, `\d{4}-\d{1,2}-\d{1,2} \d{1,2}:\d\d:\d\d\.\d{6} \[test\] Test info number 4`)
+	gold = append(gold, `\d{4}-\d{1,2}-\d{1,2} \d{1,2}:\d\d:\d\d\.\d{6} TRACE \[test\] Test trace`)
+	gold = append(gold, `\d{4}-\d{1,2}-\d{1,2} \d{1,2}:\d\d:\d\d\.\d{6} INFO \[test\] Test info`)
+	gold = append(gold, `\d{4}-\d{1,2}-\d{1,2} \d{1,2}:\d\d:\d\d\.\d{6} TRACE \[test\] Test trace number 3 after others`)
+	gold = append(gold, `\d{4}-\d{1,2}-\d{1,2} \d{1,2}:\d\d:\d\d\.\d{6} INFO \[test\] Test info number 4`)
 
 	for i, line := range logMemFile {
 		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
@@ -83,3 +182,2837 @@ func Test_LogGroup(t *testing.T) {
 		}
 	}
 }
+
+func Test_Warn(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("warn", &logMemFile, true)
+
+	Warng(group, "Test warn")
+	Warngf(group, "Test warn number %d", 2)
+
+	Done()
+
+	var gold []string
+	gold = make([]string, 0, 2)
+	gold = append(gold, timeFormat+` WARN \[warn\] Test warn`)
+	gold = append(gold, timeFormat+` WARN \[warn\] Test warn number 2`)
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("Warn failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("Warn failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_Error(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile, errMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+	errMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("error", &logMemFile, true)
+
+	Errorg(group, "Test error to output")
+	SetErrorOutput(group, &errMemFile)
+	Infog(group, "Test info")
+	Errorgf(group, "Test error number %d", 2)
+
+	Done()
+
+	if len(logMemFile) != 2 || len(errMemFile) != 1 {
+		t.Fatal("Error failed: recieved", len(logMemFile), "output lines and", len(errMemFile), "error lines")
+	}
+
+	gold := []string{
+		timeFormat + ` ERROR \[error\] Test error to output`,
+		timeFormat + ` INFO \[error\] Test info`,
+	}
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("Error failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+
+	if match, err := regexp.MatchString(timeFormat+` ERROR \[error\] Test error number 2`, errMemFile[0]); err != nil || !match {
+		t.Error("Error failed: Line mismatch on error output. Recieved:\n", errMemFile[0])
+	}
+}
+
+func Test_ErrorErr(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("errorerr", &logMemFile, true)
+	err := fmt.Errorf("open failed: %w", errors.New("denied"))
+
+	ErrorgErr(group, err)
+	ErrorgErr(group, nil)
+	SetErrorChain(true)
+	ErrorgErr(group, err)
+	SetFormat(FormatJSON)
+	ErrorgErr(group, err)
+	SetFormat(FormatText)
+	SetErrorChain(false)
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` ERROR \[errorerr\] open failed: denied\n$`,
+		timeFormat + ` ERROR \[errorerr\] nil error\n$`,
+		timeFormat + ` ERROR \[errorerr\] open failed: denied \(chain: \*fmt.wrapError: open failed: denied <- \*errors.errorString: denied\)\n$`,
+		`"msg":"open failed: denied","chain":\[\{"type":"\*fmt.wrapError","msg":"open failed: denied"\},\{"type":"\*errors.errorString","msg":"denied"\}\]\}\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("ErrorErr failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("ErrorErr failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_Errorsf(t *testing.T) {
+	std.Reset(true)
+
+	cause := errors.New("denied")
+	var msg string
+	var err error
+	lines := Capture(func() {
+		msg = Errorsf("open %s: %v", "file", cause)
+		err = ErrorfE("read %s: %w", "file", cause)
+	})
+
+	Done()
+
+	if msg != "open file: denied" {
+		t.Error("Errorsf failed: expected open file: denied, recieved", msg)
+	}
+	if err == nil || err.Error() != "read file: denied" || !errors.Is(err, cause) {
+		t.Error("ErrorfE failed: expected an error wrapping denied, recieved", err)
+	}
+
+	gold := []string{
+		`^` + timeFormat + ` ERROR open file: denied$`,
+		`^` + timeFormat + ` ERROR read file: denied$`,
+	}
+
+	if len(lines) != len(gold) {
+		t.Fatal("Errorsf failed: expected", len(gold), "lines, recieved", len(lines))
+	}
+
+	for i, line := range lines {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("Errorsf failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+// Registered once, as levels cannot be removed between runs of the tests
+var (
+	auditLevel  = RegisterLevel("AUDIT", int(LevelWarn))
+	metricLevel = RegisterLevel("METRIC", int(LevelTrace))
+)
+
+func Test_RegisterLevel(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("customlevel", &logMemFile, true)
+	SetMinLevel(LevelInfo)
+
+	func() {
+		defer func() {
+			if r := recover(); r != ErrLevelExists {
+				t.Error("RegisterLevel failed: expected ErrLevelExists panic, recieved", r)
+			}
+		}()
+		RegisterLevel("INFO", int(LevelInfo))
+	}()
+
+	Logf(group, auditLevel, "Test %s", "audit")
+	Logf(group, metricLevel, "Test metric off")
+	Logf(group, LevelInfo, "Test built-in")
+	Log(group, LevelWarn, "Test ", "print")
+	SetMinLevel(LevelError)
+	Logf(group, auditLevel, "Test audit filtered")
+	SetMinLevel(LevelInfo)
+	SetFormat(FormatJSON)
+	Logf(group, auditLevel, "Test json")
+	SetFormat(FormatText)
+
+	Done()
+
+	if auditLevel.String() != "AUDIT" || Level(99).String() != "Level(99)" {
+		t.Error("RegisterLevel failed: recieved names", auditLevel, Level(99))
+	}
+
+	gold := []string{
+		`^` + timeFormat + ` AUDIT \[customlevel\] Test audit\n$`,
+		`^` + timeFormat + ` INFO \[customlevel\] Test built-in\n$`,
+		`^` + timeFormat + ` WARN \[customlevel\] Test print\n$`,
+		`"level":"audit","group":"customlevel","msg":"Test json"`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("RegisterLevel failed: expected", len(gold), "lines, recieved", logMemFile)
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("RegisterLevel failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_GroupByName(t *testing.T) {
+	std.Reset(true)
+	defer Done()
+
+	group := RegisterGroup("byname", &memoryLog{}, true)
+
+	if id, ok := GroupByName("byname"); !ok || id != group {
+		t.Error("GroupByName failed: expected", group, "recieved", id, ok)
+	}
+
+	if id, ok := GroupByName(""); !ok || id != DefaultGroupId {
+		t.Error("GroupByName failed: expected default group, recieved", id, ok)
+	}
+
+	if _, ok := GroupByName("missing"); ok {
+		t.Error("GroupByName failed: found a group that was never registered")
+	}
+}
+
+func Test_RegisterGroupE(t *testing.T) {
+	std.Reset(true)
+	defer Done()
+
+	group, err := RegisterGroupE("registere", &memoryLog{}, true)
+	if err != nil {
+		t.Fatal("RegisterGroupE failed:", err)
+	}
+
+	again, err := RegisterGroupE("registere", &memoryLog{}, true)
+	if !errors.Is(err, ErrGroupExists) {
+		t.Error("RegisterGroupE failed: expected ErrGroupExists, recieved", err)
+	}
+	if again != group {
+		t.Error("RegisterGroupE failed: expected existing ID", group, "recieved", again)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterGroup failed: duplicate name did not panic")
+		}
+	}()
+	RegisterGroup("registere", &memoryLog{}, true)
+}
+
+func Test_FormatJSON(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("json", &logMemFile, true)
+
+	SetFormat(FormatJSON)
+	WithFields(Fields{"user": "bob", "msg": "clash"}).Infogf(group, "login %d", 1)
+	WithFields(Fields{"err": errors.New("boom"), "took": time.Second}).Infog(group, "values")
+	SetFormat(FormatText)
+	WithFields(Fields{"user": "bob", "code": 7}).Infog(group, "text")
+
+	Done()
+
+	gold := []string{
+		`^\{"time":"\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(\.\d+)?Z","level":"info","group":"json","msg":"login 1","fields.msg":"clash","user":"bob"\}` + "\n$",
+		`"msg":"values","err":"boom","took":"1s"\}` + "\n$",
+		`^` + timeFormat + ` INFO \[json\] text code=7 user=bob` + "\n$",
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("Format failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("Format failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_FormatLogfmt(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("logfmt", &logMemFile, true)
+
+	SetFormat(FormatLogfmt)
+	WithFields(Fields{"user": "bob", "level": "x=y", "empty": ""}).Infogf(group, "the %q message", "quoted")
+	Warng(group, "plain")
+	SetFormat(FormatText)
+
+	Done()
+
+	gold := []string{
+		`^time=\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(\.\d+)?Z level=info group=logfmt msg="the \\"quoted\\" message" empty="" fields.level="x=y" user=bob` + "\n$",
+		`^time=\S+ level=warn group=logfmt msg=plain` + "\n$",
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("Format failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("Format failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetFormatter(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("formatter", &logMemFile, true)
+
+	SetFormatter(func(e Entry) []byte {
+		return []byte(fmt.Sprintf("%d,%s,%s,%s\n", e.Group, e.GroupName, e.Level, e.Message))
+	})
+	Warngf(group, "Test %s", "csv")
+	SetFormatter(func(e Entry) []byte {
+		return append([]byte("> "), TextFormatter(e)...)
+	})
+	Infog(group, "Test wrapped")
+	SetFormatter(nil)
+	Infog(group, "Test default")
+
+	Done()
+
+	gold := []string{
+		fmt.Sprintf(`^%d,formatter,WARN,Test csv\n$`, group),
+		`^> ` + timeFormat + ` INFO \[formatter\] Test wrapped`,
+		`^` + timeFormat + ` INFO \[formatter\] Test default`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetFormatter failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetFormatter failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetTimeFormat(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("timeformat", &logMemFile, true)
+
+	SetTimeFormat(time.RFC3339Nano)
+	Infog(group, "Test rfc3339")
+	SetTimeFormat("")
+	Infog(group, "Test default")
+
+	Done()
+
+	gold := []string{
+		`^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(\.\d+)?Z INFO \[timeformat\] Test rfc3339`,
+		`^` + timeFormat + ` INFO \[timeformat\] Test default`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetTimeFormat failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetTimeFormat failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetTimestamps(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("timestamps", &logMemFile, true)
+
+	SetTimestamps(false)
+	Infog(group, "Test no time")
+	SetGroupPrefix(group, "myservice")
+	Infog(group, "Test prefix")
+	SetFormat(FormatJSON)
+	Infog(group, "Test json")
+	SetFormat(FormatText)
+	SetTimestamps(true)
+	Infog(group, "Test time")
+
+	Done()
+
+	gold := []string{
+		`^INFO \[timestamps\] Test no time`,
+		`^myservice INFO \[timestamps\] Test prefix`,
+		`^{"prefix":"myservice","level":"info","group":"timestamps","msg":"Test json"}`,
+		`^` + timeFormat + ` myservice INFO \[timestamps\] Test time`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetTimestamps failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetTimestamps failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetEscapeControl(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("escapecontrol", &logMemFile, true)
+
+	Infog(group, "Test raw\nsecond line")
+	SetEscapeControl(true)
+	Infog(group, "Test escaped\nsecond line\r\tindented")
+	WithFields(Fields{"stack": "main()\n\tmain.go:12", "code": 7}).Infog(group, "Test fields")
+	SetEscapeControl(false)
+	SetFormat(FormatJSON)
+	Infog(group, "Test json\nsecond line")
+	SetFormat(FormatText)
+
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` INFO \[escapecontrol\] Test raw\nsecond line\n$`,
+		`^` + timeFormat + ` INFO \[escapecontrol\] Test escaped\\nsecond line\\r\\tindented\n$`,
+		`^` + timeFormat + ` INFO \[escapecontrol\] Test fields code=7 stack=main\(\)\\n\\tmain.go:12\n$`,
+		`^{"time":"[^"]+","level":"info","group":"escapecontrol","msg":"Test json\\nsecond line"}\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetEscapeControl failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetEscapeControl failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetColor(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("color", &logMemFile, true)
+
+	SetColor(ColorAuto)
+	Infog(group, "Test auto")
+	SetColor(ColorAlways)
+	Warng(group, "Test always")
+	SetColor(ColorNever)
+	Infog(group, "Test never")
+
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` INFO \[color\] Test auto`,
+		`^` + timeFormat + ` \x1b\[33mWARN\x1b\[0m \[color\] Test always`,
+		`^` + timeFormat + ` INFO \[color\] Test never`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetColor failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Errorf("SetColor failed: Line mismatch on line %d Recieved:\n %q", i+1, line)
+		}
+	}
+}
+
+func Test_SetTimeMode(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("timemode", &logMemFile, true)
+
+	SetTimeMode(TimeRelative)
+	Infog(group, "Test relative")
+	SetTimeMode(TimeAbsolute)
+	Infog(group, "Test absolute")
+
+	Done()
+
+	gold := []string{
+		`^\+\d\d:\d\d:\d\d\.\d{6} INFO \[timemode\] Test relative`,
+		`^` + timeFormat + ` INFO \[timemode\] Test absolute`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetTimeMode failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetTimeMode failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+
+	if stamp := relativeTime(startTime.Add(time.Hour + 2*time.Minute + 3*time.Second + 4*time.Microsecond)); stamp != "+01:02:03.000004" {
+		t.Error("SetTimeMode failed: expected +01:02:03.000004, recieved", stamp)
+	}
+}
+
+func Test_FormatLengthPrefixed(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 2)
+
+	group := RegisterGroup("framed", &logMemFile, true)
+
+	SetFormat(FormatLengthPrefixed)
+	Infog(group, "Test first\nline")
+	Infog(group, "Test second")
+	SetFormat(FormatText)
+
+	Done()
+
+	stream := strings.NewReader(strings.Join(logMemFile, ""))
+	gold := []string{
+		`^` + timeFormat + ` INFO \[framed\] Test first\nline$`,
+		`^` + timeFormat + ` INFO \[framed\] Test second$`,
+	}
+
+	for i := range gold {
+		line, err := ReadFrame(stream)
+		if err != nil {
+			t.Fatal("FormatLengthPrefixed failed: frame", i+1, "recieved error", err)
+		}
+		if match, err := regexp.Match(gold[i], line); err != nil || !match {
+			t.Error("FormatLengthPrefixed failed: Line mismatch on line", i+1, "Recieved:\n", string(line))
+		}
+	}
+
+	if _, err := ReadFrame(stream); err != io.EOF {
+		t.Error("FormatLengthPrefixed failed: expected io.EOF, recieved", err)
+	}
+	if _, err := ReadFrame(strings.NewReader("\x00\x00\x00\x05abc")); err != io.ErrUnexpectedEOF {
+		t.Error("FormatLengthPrefixed failed: expected io.ErrUnexpectedEOF, recieved", err)
+	}
+}
+
+func Test_Infokv(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 3)
+
+	group := RegisterGroup("kv", &logMemFile, true)
+
+	Infokv(group, "Test pairs", "user", "bob", 7, true)
+	Warnkv(group, "Test odd", "user", "bob", "dangling")
+	SetFormat(FormatJSON)
+	Infokv(group, "Test json", "user", "bob")
+	SetFormat(FormatText)
+
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` INFO \[kv\] Test pairs 7=true user=bob\n$`,
+		`^` + timeFormat + ` WARN \[kv\] Test odd !BADKEY=dangling user=bob\n$`,
+		`"msg":"Test json","user":"bob"\}\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("Infokv failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("Infokv failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_NewEntry(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("entry", &logMemFile, true)
+
+	NewEntry(group).Str("user", "bob").Int("code", 7).Bool("ok", true).Msg("Test builder")
+	NewEntry(group).Level(LevelWarn).Dur("took", time.Second).Err(nil).Msgf("Test %s", "formatted")
+	SetFormat(FormatJSON)
+	NewEntry(group).Str("user", "bob").Float64("ratio", 0.5).Msg("Test json")
+	NewEntry(group).Err(errors.New("boom")).Msg("Test json error")
+	SetFormat(FormatText)
+
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` INFO \[entry\] Test builder code=7 ok=true user=bob\n$`,
+		`^` + timeFormat + ` WARN \[entry\] Test formatted took=1s\n$`,
+		`"msg":"Test json","ratio":0.5,"user":"bob"\}\n$`,
+		`"msg":"Test json error","error":"boom"\}\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("NewEntry failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("NewEntry failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetMirror(t *testing.T) {
+	std.Reset(true)
+
+	var firstLog, secondLog, mirrorLog memoryLog
+
+	first := RegisterGroup("mirror.first", &firstLog, true)
+	second := RegisterDiscardGroup("mirror.second", true)
+	off := RegisterGroup("mirror.off", &secondLog, false)
+
+	SetMirror(&mirrorLog)
+	Infog(first, "Test first")
+	Errorg(second, "Test second")
+	Infog(off, "Test off")
+	ClearMirror()
+	Infog(first, "Test cleared")
+
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` INFO \[mirror.first\] Test first\n$`,
+		`^` + timeFormat + ` ERROR \[mirror.second\] Test second\n$`,
+	}
+
+	if len(firstLog) != 2 || len(mirrorLog) != len(gold) {
+		t.Fatal("SetMirror failed: expected", len(gold), "mirrored lines, recieved", mirrorLog)
+	}
+
+	for i, line := range mirrorLog {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetMirror failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetFieldSeparator(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 3)
+
+	group := RegisterGroup("separator", &logMemFile, true)
+
+	SetFieldSeparator("\t")
+	WithFields(Fields{"a": 1, "b": "two"}).Infog(group, "Test tab")
+	SetFieldSeparator(" | ")
+	Infog(group, "Test pipe")
+	SetFieldSeparator("")
+	Infog(group, "Test default")
+
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + `\tINFO\t\[separator\]\tTest tab\ta=1\tb=two\n$`,
+		`^` + timeFormat + ` \| INFO \| \[separator\] \| Test pipe\n$`,
+		`^` + timeFormat + ` INFO \[separator\] Test default\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetFieldSeparator failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetFieldSeparator failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_EnableHostname(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 3)
+
+	group := RegisterGroup("hostname", &logMemFile, true)
+
+	EnableHostname(true)
+	Infog(group, "Test text")
+	SetFormat(FormatLogfmt)
+	Infog(group, "Test logfmt")
+	SetFormat(FormatText)
+	EnableHostname(false)
+	Infog(group, "Test off")
+
+	Done()
+
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skip("hostname lookup failed:", err)
+	}
+
+	gold := []string{
+		`^` + timeFormat + ` INFO \[hostname\] \[host=` + regexp.QuoteMeta(host) + `\] Test text\n$`,
+		`^time=\S+ level=info group=hostname host=` + regexp.QuoteMeta(host) + ` msg="Test logfmt"\n$`,
+		`^` + timeFormat + ` INFO \[hostname\] Test off\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("EnableHostname failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("EnableHostname failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_EnablePID(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 3)
+
+	group := RegisterGroup("pid", &logMemFile, true)
+
+	EnablePID(true)
+	Infog(group, "Test text")
+	SetFormat(FormatJSON)
+	Infog(group, "Test json")
+	SetFormat(FormatText)
+	EnablePID(false)
+	Infog(group, "Test off")
+
+	Done()
+
+	pid := strconv.Itoa(os.Getpid())
+	gold := []string{
+		`^` + timeFormat + ` INFO \[pid\] \[pid=` + pid + `\] Test text\n$`,
+		`^\{"time":"[^"]+","level":"info","group":"pid","pid":` + pid + `,"msg":"Test json"\}\n$`,
+		`^` + timeFormat + ` INFO \[pid\] Test off\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("EnablePID failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("EnablePID failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_WriterFunc(t *testing.T) {
+	std.Reset(true)
+
+	var lines []string
+	group := RegisterGroup("writerfunc", WriterFunc(func(p []byte) (int, error) {
+		lines = append(lines, string(p))
+		return len(p), nil
+	}), true)
+
+	Infog(group, "Test func")
+
+	Done()
+
+	if len(lines) != 1 {
+		t.Fatal("WriterFunc failed: expected 1 line, recieved", len(lines))
+	}
+	if match, err := regexp.MatchString(`^`+timeFormat+` INFO \[writerfunc\] Test func\n$`, lines[0]); err != nil || !match {
+		t.Error("WriterFunc failed: Line mismatch Recieved:\n", lines[0])
+	}
+}
+
+func Test_InfoGroups(t *testing.T) {
+	std.Reset(true)
+
+	var auditLog, securityLog, offLog memoryLog
+
+	audit := RegisterGroup("groups.audit", &auditLog, true)
+	security := RegisterGroup("groups.security", &securityLog, true)
+	off := RegisterGroup("groups.off", &offLog, false)
+
+	count := 0
+	groups := []int{audit, security, off}
+	InfoGroups(groups, "Test %v", countingStringer{&count})
+	groups[0] = off
+
+	Done()
+
+	if count != 1 {
+		t.Error("InfoGroups failed: expected 1 formatting, recieved", count)
+	}
+	if len(auditLog) != 1 || len(securityLog) != 1 || len(offLog) != 0 {
+		t.Fatal("InfoGroups failed: recieved", auditLog, securityLog, offLog)
+	}
+	if stamp := auditLog[0][:strings.Index(auditLog[0], " INFO")]; !strings.HasPrefix(securityLog[0], stamp+" INFO [groups.security] Test counted") {
+		t.Error("InfoGroups failed: Line mismatch Recieved:\n", auditLog[0], securityLog[0])
+	}
+}
+
+func Test_SetClock(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 1)
+
+	group := RegisterGroup("clock", &logMemFile, true)
+
+	SetClock(func() time.Time {
+		return time.Date(2024, 3, 5, 10, 20, 30, 123456000, time.Local)
+	})
+	Infog(group, "Test fixed")
+	SetClock(nil)
+
+	Done()
+
+	gold := "2024-3-5 10:20:30.123456 INFO [clock] Test fixed\n"
+	if len(logMemFile) != 1 || logMemFile[0] != gold {
+		t.Error("SetClock failed: expected", gold, "recieved", logMemFile)
+	}
+}
+
+func Test_SetTimePrecision(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("timeprecision", &logMemFile, true)
+
+	SetTimePrecision(PrecisionSeconds)
+	Infog(group, "Test seconds")
+	SetTimePrecision(PrecisionMillis)
+	Infog(group, "Test millis")
+	SetTimePrecision(PrecisionNanos)
+	Infog(group, "Test nanos")
+	SetTimePrecision(PrecisionMicros)
+	Infog(group, "Test micros")
+
+	Done()
+
+	date := `^\d{4}-\d{1,2}-\d{1,2} \d{1,2}:\d\d:\d\d`
+	gold := []string{
+		date + ` INFO \[timeprecision\] Test seconds`,
+		date + `\.\d{3} INFO \[timeprecision\] Test millis`,
+		date + `\.\d{9} INFO \[timeprecision\] Test nanos`,
+		`^` + timeFormat + ` INFO \[timeprecision\] Test micros`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetTimePrecision failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetTimePrecision failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetTimeZone(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("timezone", &logMemFile, true)
+
+	SetTimeFormat("-0700")
+	SetTimeZone(time.FixedZone("test", 3*60*60))
+	Infog(group, "Test fixed zone")
+	SetTimeZone(nil)
+	Infog(group, "Test utc")
+	SetTimeFormat("")
+
+	Done()
+
+	gold := []string{
+		`^\+0300 INFO \[timezone\] Test fixed zone`,
+		`^\+0000 INFO \[timezone\] Test utc`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetTimeZone failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetTimeZone failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_EnableSequence(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("sequence", &logMemFile, true)
+
+	EnableSequence(true)
+	Infog(group, "Test first")
+	Infog(group, "Test second")
+	SetFormat(FormatJSON)
+	Infog(group, "Test json")
+	SetFormat(FormatText)
+	EnableSequence(false)
+	Infog(group, "Test unnumbered")
+
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` #(\d{6}) INFO \[sequence\] Test first`,
+		`^` + timeFormat + ` #(\d{6}) INFO \[sequence\] Test second`,
+		`^{"time":"[^"]+","seq":(\d+),"level":"info","group":"sequence","msg":"Test json"}`,
+		`^` + timeFormat + ` INFO \[sequence\] Test unnumbered`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("EnableSequence failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	var seqs []int
+	for i, line := range logMemFile {
+		match := regexp.MustCompile(gold[i]).FindStringSubmatch(line)
+		if match == nil {
+			t.Error("EnableSequence failed: Line mismatch on line", i+1, "Recieved:\n", line)
+			continue
+		}
+		if len(match) > 1 {
+			var seq int
+			fmt.Sscanf(match[1], "%d", &seq)
+			seqs = append(seqs, seq)
+		}
+	}
+
+	for i := 1; i < len(seqs); i++ {
+		if seqs[i] != seqs[i-1]+1 {
+			t.Error("EnableSequence failed: expected consecutive numbers, recieved", seqs)
+		}
+	}
+}
+
+func Test_EnableCaller(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("caller", &logMemFile, true)
+
+	EnableCaller(true)
+	Infog(group, "Test caller")
+	WithFields(Fields{"key": "value"}).Infog(group, "Test fields caller")
+	EnableCaller(false)
+	Infog(group, "Test no caller")
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` INFO \[caller\] trace_test\.go:\d+ Test caller`,
+		timeFormat + ` INFO \[caller\] trace_test\.go:\d+ Test fields caller key=value`,
+		timeFormat + ` INFO \[caller\] Test no caller`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("EnableCaller failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("EnableCaller failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetCallerMode(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("callermode", &logMemFile, true)
+
+	SetCallerMode(CallerFile)
+	Infog(group, "Test file")
+	SetCallerMode(CallerFunc)
+	Infog(group, "Test func")
+	SetCallerMode(CallerFullFunc)
+	Infog(group, "Test full func")
+	SetCallerMode(CallerNone)
+	Infog(group, "Test none")
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` INFO \[callermode\] trace_test\.go:\d+ Test file`,
+		timeFormat + ` INFO \[callermode\] trace\.Test_SetCallerMode Test func`,
+		timeFormat + ` INFO \[callermode\] \S+/trace\.Test_SetCallerMode Test full func`,
+		timeFormat + ` INFO \[callermode\] Test none`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetCallerMode failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetCallerMode failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+// wrappedInfo stands in for a helper of a package wrapping trace
+func wrappedInfo(group int, msg string) {
+	Infog(group, msg)
+}
+
+func Test_SetCallerSkip(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 2)
+
+	group := RegisterGroup("callerskip", &logMemFile, true)
+
+	SetCallerMode(CallerFunc)
+	wrappedInfo(group, "Test wrapper")
+	SetCallerSkip(1)
+	wrappedInfo(group, "Test skipped wrapper")
+	SetCallerSkip(0)
+	SetCallerMode(CallerNone)
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` INFO \[callerskip\] trace\.wrappedInfo Test wrapper`,
+		timeFormat + ` INFO \[callerskip\] trace\.Test_SetCallerSkip Test skipped wrapper`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetCallerSkip failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetCallerSkip failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_EnableDefault(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+	SetDefaultGroup(&logMemFile)
+
+	EnableDefault(false)
+	Info("Test dropped")
+	Flush()
+	if IsGroupEnabled(DefaultGroupId) {
+		t.Error("EnableDefault failed: default group still enabled")
+	}
+	EnableDefault(true)
+	Info("Test enabled")
+
+	Done()
+
+	if len(logMemFile) != 1 {
+		t.Fatal("EnableDefault failed: expected 1 line, recieved", len(logMemFile))
+	}
+	if match, err := regexp.MatchString(timeFormat+` INFO Test enabled`, logMemFile[0]); err != nil || !match {
+		t.Error("EnableDefault failed: Line mismatch. Recieved:\n", logMemFile[0])
+	}
+}
+
+func Test_SetGroupOutput(t *testing.T) {
+	std.Reset(true)
+
+	var first, second memoryLog
+	group := RegisterGroup("groupoutput", &first, true)
+
+	const count = 1000
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < count; i++ {
+			Infogf(group, "Test line %d", i)
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		if i%2 == 0 {
+			SetGroupOutput(group, &second)
+		} else {
+			SetGroupOutput(group, &first)
+		}
+	}
+
+	wg.Wait()
+	Done()
+
+	if total := len(first) + len(second); total != count {
+		t.Fatal("SetGroupOutput failed: expected", count, "lines, recieved", total)
+	}
+
+	func() {
+		defer func() {
+			if err := recover(); err != ErrUnknownGroup {
+				t.Error("SetGroupOutput failed: expected ErrUnknownGroup, recieved", err)
+			}
+		}()
+		SetGroupOutput(group+1000, &first)
+	}()
+
+	for _, line := range append(first, second...) {
+		if match, err := regexp.MatchString(`^`+timeFormat+` INFO \[groupoutput\] Test line \d+\n$`, line); err != nil || !match {
+			t.Error("SetGroupOutput failed: Line mismatch. Recieved:\n", line)
+		}
+	}
+}
+
+func Test_LevelString(t *testing.T) {
+	gold := map[Level]string{
+		LevelTrace: "TRACE",
+		LevelInfo:  "INFO",
+		LevelWarn:  "WARN",
+		LevelError: "ERROR",
+		Level(0):   "Level(0)",
+		Level(9):   "Level(9)",
+	}
+
+	for lvl, name := range gold {
+		if lvl.String() != name {
+			t.Error("Level.String failed: expected", name, "recieved", lvl.String())
+		}
+	}
+
+	if !(LevelTrace < LevelInfo && LevelInfo < LevelWarn && LevelWarn < LevelError) {
+		t.Error("Level failed: levels are not ordered by severity")
+	}
+}
+
+func Test_SetMinLevel(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("minlevel", &logMemFile, true)
+
+	SetMinLevel(LevelWarn)
+	Infog(group, "Test info dropped")
+	Warng(group, "Test warn")
+	EnableTrace(true)
+	Flush()
+	if !IsTraceEnabled() {
+		t.Error("SetMinLevel failed: EnableTrace did not lower the minimum level")
+	}
+	Traceg(group, "Test trace")
+	SetMinLevel(LevelInfo)
+	Traceg(group, "Test trace dropped")
+	Infog(group, "Test info")
+
+	Done()
+
+	if IsTraceEnabled() {
+		t.Error("SetMinLevel failed: trace still enabled at LevelInfo")
+	}
+
+	gold := []string{
+		timeFormat + ` WARN \[minlevel\] Test warn`,
+		timeFormat + ` TRACE \[minlevel\] Test trace`,
+		timeFormat + ` INFO \[minlevel\] Test info`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetMinLevel failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetMinLevel failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetGroupLevel(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("grouplevel", &logMemFile, true)
+	EnableTrace(true)
+
+	SetGroupLevel(group, LevelWarn)
+	Traceg(group, "Test trace dropped")
+	Infog(group, "Test info dropped")
+	Warng(group, "Test warn")
+	Errorg(group, "Test error")
+	SetGroupLevel(group, LevelTrace)
+	Traceg(group, "Test trace")
+
+	EnableTrace(false)
+	Done()
+
+	gold := []string{
+		timeFormat + ` WARN \[grouplevel\] Test warn`,
+		timeFormat + ` ERROR \[grouplevel\] Test error`,
+		timeFormat + ` TRACE \[grouplevel\] Test trace`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetGroupLevel failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetGroupLevel failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetGroupPrefix(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("groupprefix", &logMemFile, true)
+
+	SetGroupPrefix(group, "myservice")
+	Infog(group, "Test prefix")
+	SetFormat(FormatJSON)
+	Infog(group, "Test json prefix")
+	SetFormat(FormatText)
+	SetGroupPrefix(group, "")
+	Infog(group, "Test no prefix")
+
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` myservice INFO \[groupprefix\] Test prefix`,
+		`^{"time":"[^"]+","prefix":"myservice","level":"info","group":"groupprefix","msg":"Test json prefix"}`,
+		`^` + timeFormat + ` INFO \[groupprefix\] Test no prefix`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetGroupPrefix failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetGroupPrefix failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetRateLimit(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("ratelimit", &logMemFile, true)
+
+	SetRateLimit(group, 2)
+	for i := 1; i <= 5; i++ {
+		Infogf(group, "Test message %d", i)
+	}
+	Flush()
+
+	if count := SuppressedCount(group); count != 3 {
+		t.Error("SetRateLimit failed: expected 3 suppressed messages, recieved", count)
+	}
+
+	SetRateLimit(group, 0)
+	Infog(group, "Test unlimited")
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` INFO \[ratelimit\] Test message 1`,
+		timeFormat + ` INFO \[ratelimit\] Test message 2`,
+		timeFormat + ` WARN \[ratelimit\] \.\.\. 3 messages suppressed`,
+		timeFormat + ` INFO \[ratelimit\] Test unlimited`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetRateLimit failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetRateLimit failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetDedup(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("dedup", &logMemFile, true)
+
+	SetDedup(group, true)
+	for i := 0; i < 4; i++ {
+		Warngf(group, "Test retry %d", 1)
+	}
+	Infog(group, "Test different")
+	Infog(group, "Test different")
+	Flush()
+	SetDedup(group, false)
+	Infog(group, "Test different")
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` WARN \[dedup\] Test retry 1`,
+		timeFormat + ` WARN \[dedup\] last message repeated 3 times`,
+		timeFormat + ` INFO \[dedup\] Test different`,
+		timeFormat + ` INFO \[dedup\] last message repeated 1 times`,
+		timeFormat + ` INFO \[dedup\] Test different`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetDedup failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetDedup failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetSampleRate(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("samplerate", &logMemFile, true)
+	EnableTrace(true)
+
+	SetSampleRate(group, 3)
+	for i := 1; i <= 7; i++ {
+		Tracegf(group, "Test trace %d", i)
+	}
+	Infog(group, "Test info")
+	Infog(group, "Test info")
+	EnableTrace(false)
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` TRACE \[samplerate\] Test trace 3`,
+		timeFormat + ` TRACE \[samplerate\] Test trace 6`,
+		timeFormat + ` INFO \[samplerate\] Test info`,
+		timeFormat + ` INFO \[samplerate\] Test info`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetSampleRate failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetSampleRate failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetMetricsHook(t *testing.T) {
+	std.Reset(true)
+
+	group := RegisterGroup("metrics", &memoryLog{}, true)
+	SetGroupLevel(group, LevelInfo)
+
+	counts := make(map[Level]int)
+	SetMetricsHook(func(g int, lvl Level) {
+		if g == group {
+			counts[lvl]++
+		}
+	})
+
+	Traceg(group, "Test trace dropped")
+	Infog(group, "Test info")
+	Infog(group, "Test info")
+	Errorg(group, "Test error")
+	SetMetricsHook(nil)
+	Infog(group, "Test uncounted")
+
+	Done()
+
+	if counts[LevelTrace] != 0 || counts[LevelInfo] != 2 || counts[LevelError] != 1 {
+		t.Error("SetMetricsHook failed: Recieved counts", counts)
+	}
+}
+
+func Test_SetLevelOutput(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile, debugMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+	debugMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("leveloutput", &logMemFile, true)
+	EnableTrace(true)
+
+	SetLevelOutput(group, LevelTrace, &debugMemFile)
+	Traceg(group, "Test trace")
+	Infog(group, "Test info")
+	SetLevelOutput(group, LevelTrace, nil)
+	Traceg(group, "Test trace restored")
+	EnableTrace(false)
+
+	Done()
+
+	if len(debugMemFile) != 1 {
+		t.Fatal("SetLevelOutput failed: expected 1 line on the level output, recieved", len(debugMemFile))
+	}
+	if match, err := regexp.MatchString(timeFormat+` TRACE \[leveloutput\] Test trace`, debugMemFile[0]); err != nil || !match {
+		t.Error("SetLevelOutput failed: Line mismatch on level output. Recieved:\n", debugMemFile[0])
+	}
+
+	gold := []string{
+		timeFormat + ` INFO \[leveloutput\] Test info`,
+		timeFormat + ` TRACE \[leveloutput\] Test trace restored`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetLevelOutput failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetLevelOutput failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_FlushEveryOutput(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	var errLog, levelLog, mirrorLog flushingLog
+
+	group := RegisterGroup("flushall", &logMemFile, true)
+	SetErrorOutput(group, &errLog)
+	SetLevelOutput(group, LevelWarn, &levelLog)
+	SetMirror(&mirrorLog)
+	Flush()
+	ClearMirror()
+	SetLevelOutput(group, LevelWarn, nil)
+	SetErrorOutput(group, nil)
+
+	Done()
+
+	if errLog.flushes == 0 || levelLog.flushes == 0 || mirrorLog.flushes == 0 {
+		t.Error("Flush failed: expected each output flushed, recieved", errLog.flushes, levelLog.flushes, mirrorLog.flushes)
+	}
+}
+
+func Test_SetBatch(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("batch", &logMemFile, true)
+
+	SetBatch(group, true)
+	SetSynchronous(true)
+	Infog(group, "Test first")
+	Infog(group, "Test second")
+
+	if len(logMemFile) != 0 {
+		t.Error("SetBatch failed: expected lines to be buffered, recieved", len(logMemFile))
+	}
+
+	Flush()
+	SetSynchronous(false)
+
+	if len(logMemFile) != 1 {
+		t.Fatal("SetBatch failed: expected one write of the buffered lines, recieved", len(logMemFile))
+	}
+
+	SetBatch(group, false)
+	Infog(group, "Test unbatched")
+
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` INFO \[batch\] Test first\n` + timeFormat + ` INFO \[batch\] Test second\n$`,
+		`^` + timeFormat + ` INFO \[batch\] Test unbatched\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetBatch failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetBatch failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetFlushInterval(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	group := RegisterGroup("flushinterval", &logMemFile, true)
+
+	SetBatch(group, true)
+	SetFlushInterval(10 * time.Millisecond)
+	Infog(group, "Test flushed by ticker")
+
+	flushed := false
+	for deadline := time.Now().Add(5 * time.Second); !flushed && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		std.mu.Lock()
+		flushed = len(logMemFile) == 1
+		std.mu.Unlock()
+	}
+
+	SetFlushInterval(0)
+	SetBatch(group, false)
+	Done()
+
+	if !flushed {
+		t.Error("SetFlushInterval failed: batched line not written by the ticker")
+	}
+}
+
+func Test_GroupStats(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	group := RegisterGroup("stats", &logMemFile, true)
+
+	SetDedup(group, true)
+	Infog(group, "Test emitted")
+	Infog(group, "Test emitted")
+	Infog(group, "Test emitted")
+	SetDedup(group, false)
+	SetRateLimit(group, 1)
+	Infog(group, "Test limited 1")
+	Infog(group, "Test limited 2")
+	SetRateLimit(group, 0)
+	std.countDropped(group)
+
+	stats := GroupStats(group)
+	missing := GroupStats(-1)
+
+	Done()
+
+	gold := Stats{Emitted: 2, Dropped: 1, RateLimited: 1, Deduped: 2}
+	if stats != gold {
+		t.Error("GroupStats failed: expected", gold, "recieved", stats)
+	}
+	if missing != (Stats{}) {
+		t.Error("GroupStats failed: expected zero stats for unknown group, recieved", missing)
+	}
+}
+
+func Test_SetWriteRetry(t *testing.T) {
+	std.Reset(true)
+
+	logMemFile := &flakyLog{failures: 2}
+	var failed []int
+
+	group := RegisterGroup("writeretry", logMemFile, true)
+
+	SetErrorHandler(func(group int, err error) {
+		failed = append(failed, group)
+	})
+	SetWriteRetry(group, 2, time.Millisecond)
+	Infog(group, "Test retried")
+	Flush()
+
+	logMemFile.failures = 3
+	Infog(group, "Test given up")
+	SetWriteRetry(group, 0, 0)
+	SetErrorHandler(nil)
+
+	Done()
+
+	if len(logMemFile.memoryLog) != 1 {
+		t.Fatal("SetWriteRetry failed: expected 1 line, recieved", logMemFile.memoryLog)
+	}
+	if match, err := regexp.MatchString(`^`+timeFormat+` INFO \[writeretry\] Test retried\n$`, logMemFile.memoryLog[0]); err != nil || !match {
+		t.Error("SetWriteRetry failed: Line mismatch Recieved:\n", logMemFile.memoryLog[0])
+	}
+	if len(failed) != 1 || failed[0] != group {
+		t.Error("SetWriteRetry failed: expected one reported failure, recieved", failed)
+	}
+}
+
+func Test_SetSyncWrites(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile syncingLog
+
+	group := RegisterGroup("syncwrites", &logMemFile, true)
+
+	Infog(group, "Test not synced")
+	SetSyncWrites(group, true)
+	Infog(group, "Test synced")
+	Infog(group, "Test synced again")
+	SetSyncWrites(group, false)
+	Infog(group, "Test not synced again")
+
+	Done()
+
+	if len(logMemFile.memoryLog) != 4 {
+		t.Fatal("SetSyncWrites failed: expected 4 lines, recieved", len(logMemFile.memoryLog))
+	}
+
+	if logMemFile.syncs != 2 {
+		t.Error("SetSyncWrites failed: expected 2 syncs, recieved", logMemFile.syncs)
+	}
+}
+
+func Test_SetRedactor(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("redactor", &logMemFile, true)
+
+	email := regexp.MustCompile(`[\w.]+@[\w.]+`)
+	SetRedactor(func(msg string) string {
+		return email.ReplaceAllString(msg, "[redacted]")
+	})
+	Infogf(group, "Test login %s", "bob@example.com")
+	Warng(group, "Test reset for ", "alice@example.com")
+	SetRedactor(nil)
+	Infog(group, "Test kept carol@example.com")
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` INFO \[redactor\] Test login \[redacted\]\n$`,
+		timeFormat + ` WARN \[redactor\] Test reset for \[redacted\]\n$`,
+		timeFormat + ` INFO \[redactor\] Test kept carol@example.com\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetRedactor failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetRedactor failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetMaxMessageLength(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("maxlength", &logMemFile, true)
+
+	SetMaxMessageLength(10)
+	Infog(group, "Test short")
+	Infog(group, "Test long message")
+	Infog(group, "Test ééé")
+	SetMaxMessageLength(0)
+	Infog(group, "Test long message")
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` INFO \[maxlength\] Test short\n$`,
+		timeFormat + ` INFO \[maxlength\] Test long \.\.\.\(truncated, 17 bytes\)\n$`,
+		timeFormat + ` INFO \[maxlength\] Test éé\.\.\.\(truncated, 11 bytes\)\n$`,
+		timeFormat + ` INFO \[maxlength\] Test long message\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetMaxMessageLength failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetMaxMessageLength failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_Flush(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("flush", &logMemFile, true)
+
+	Infog(group, "Test before flush")
+	Flush()
+	if len(logMemFile) != 1 {
+		t.Error("Flush failed: expected 1 line, recieved", len(logMemFile))
+	}
+
+	Infog(group, "Test after flush")
+	Done()
+
+	if len(logMemFile) != 2 {
+		t.Error("Flush failed: expected 2 lines, recieved", len(logMemFile))
+	}
+}
+
+func Test_OverflowPolicy(t *testing.T) {
+	std.Reset(true)
+
+	blocker := &blockingLog{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	group := RegisterGroup("overflow", blocker, true)
+
+	SetOverflowPolicy(PolicyDrop)
+	defer SetOverflowPolicy(PolicyBlock)
+
+	before := DroppedCount()
+
+	Infog(group, "Test blocks the log goroutine")
+	<-blocker.entered
+
+	for i := 0; i < chanBufSize+10; i++ {
+		Infog(group, "Test fill")
+	}
+
+	if dropped := DroppedCount() - before; dropped != 10 {
+		t.Error("OverflowPolicy failed: expected 10 dropped, recieved", dropped)
+	}
+
+	close(blocker.release)
+	Done()
+}
+
+func Test_Reset(t *testing.T) {
+	std.Reset(true)
+
+	closer := &closingLog{}
+	group := RegisterGroup("reset", closer, true)
+
+	Infog(group, "Test before done")
+	Done()
+	Done()
+
+	Reset(false)
+	Infog(group, "Test after reset")
+	Reset(true)
+
+	if !closer.closed {
+		t.Error("Reset failed: output of cleared group was not closed")
+	}
+	if _, ok := GroupByName("reset"); ok {
+		t.Error("Reset failed: group was not cleared")
+	}
+	if groups := ListGroups(); len(groups) != 1 || groups[0].ID != DefaultGroupId {
+		t.Error("Reset failed: expected only the default group, recieved", groups)
+	}
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` INFO \[reset\] Test before done`,
+		timeFormat + ` INFO \[reset\] Test after reset`,
+	}
+
+	if len(closer.memoryLog) != len(gold) {
+		t.Fatal("Reset failed: expected", len(gold), "lines, recieved", len(closer.memoryLog))
+	}
+
+	for i, line := range closer.memoryLog {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("Reset failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_ClearGroups(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+	SetDefaultGroup(&logMemFile)
+
+	closer := &closingLog{}
+	group := RegisterGroup("cleargroups", closer, true)
+	Infog(group, "Test before clear")
+
+	ClearGroups()
+
+	if !closer.closed {
+		t.Error("ClearGroups failed: output of removed group was not closed")
+	}
+	if _, ok := GroupByName("cleargroups"); ok {
+		t.Error("ClearGroups failed: group was not removed")
+	}
+
+	Info("Test default group")
+	SetDefaultGroup(os.Stdout)
+	Done()
+
+	if len(closer.memoryLog) != 1 {
+		t.Error("ClearGroups failed: expected 1 line before clearing, recieved", len(closer.memoryLog))
+	}
+	if len(logMemFile) != 1 {
+		t.Fatal("ClearGroups failed: expected 1 line on the default group, recieved", len(logMemFile))
+	}
+	if match, err := regexp.MatchString(timeFormat+` INFO Test default group`, logMemFile[0]); err != nil || !match {
+		t.Error("ClearGroups failed: Line mismatch. Recieved:\n", logMemFile[0])
+	}
+}
+
+func Test_Capture(t *testing.T) {
+	std.Reset(true)
+	defer Done()
+
+	lines := Capture(func() {
+		Info("Test captured")
+		Errorf("Test error %d", 2)
+	})
+
+	gold := []string{
+		`^` + timeFormat + ` INFO Test captured$`,
+		`^` + timeFormat + ` ERROR Test error 2$`,
+	}
+
+	if len(lines) != len(gold) {
+		t.Fatal("Capture failed: expected", len(gold), "lines, recieved", len(lines))
+	}
+
+	for i, line := range lines {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("Capture failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+
+	func() {
+		defer func() { recover() }()
+		Capture(func() { panic("Test panic") })
+	}()
+
+	if lines := Capture(func() {}); len(lines) != 0 {
+		t.Error("Capture failed: expected no lines, recieved", lines)
+	}
+	if g := std.groups[DefaultGroupId]; len(g.outputs) != 1 || g.errOutput == nil {
+		t.Error("Capture failed: default group outputs were not restored")
+	}
+}
+
+func Test_DoneTimeout(t *testing.T) {
+	std.Reset(true)
+
+	blocker := &blockingLog{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	group := RegisterGroup("donetimeout", blocker, true)
+
+	Infog(group, "Test blocks the log goroutine")
+	<-blocker.entered
+
+	if err := DoneTimeout(10 * time.Millisecond); !errors.Is(err, ErrDoneTimeout) {
+		t.Error("DoneTimeout failed: expected ErrDoneTimeout, recieved", err)
+	}
+
+	close(blocker.release)
+	<-std.stopped
+
+	std.Reset(true)
+	Info("Test drained")
+	if err := DoneTimeout(time.Second); err != nil {
+		t.Error("DoneTimeout failed: expected nil, recieved", err)
+	}
+}
+
+func Test_SetBufferSize(t *testing.T) {
+	std.Reset(true)
+	defer func() {
+		std.bufferSize = chanBufSize
+	}()
+
+	if err := SetBufferSize(0); !errors.Is(err, ErrBufferSize) {
+		t.Error("SetBufferSize failed: expected ErrBufferSize, recieved", err)
+	}
+
+	if err := SetBufferSize(16); err != nil {
+		t.Fatal("SetBufferSize failed:", err)
+	}
+	if cap(std.logstream) != 16 {
+		t.Error("SetBufferSize failed: expected capacity 16, recieved", cap(std.logstream))
+	}
+
+	group := RegisterGroup("buffersize", &memoryLog{}, true)
+	Infog(group, "Test makes the stream active")
+
+	if err := SetBufferSize(32); !errors.Is(err, ErrStreamActive) {
+		t.Error("SetBufferSize failed: expected ErrStreamActive, recieved", err)
+	}
+
+	Done()
+}
+
+func Test_UnregisterGroup(t *testing.T) {
+	std.Reset(true)
+
+	logMemFile := &closingLog{}
+	group := RegisterGroup("unregister", logMemFile, true)
+
+	Infog(group, "Test before unregister")
+	UnregisterGroup(group)
+	Infog(group, "Test after unregister")
+	Infog(1000, "Test unknown group")
+	EnableGroup(group, true)
+
+	Done()
+
+	if len(logMemFile.memoryLog) != 1 {
+		t.Error("UnregisterGroup failed: expected 1 line, recieved", len(logMemFile.memoryLog))
+	}
+	if !logMemFile.closed {
+		t.Error("UnregisterGroup failed: writer was not closed")
+	}
+	if _, ok := GroupByName("unregister"); ok {
+		t.Error("UnregisterGroup failed: group is still registered")
+	}
+}
+
+func Test_RegisterFileGroup(t *testing.T) {
+	std.Reset(true)
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	group, err := RegisterFileGroup("file", path, true)
+	if err != nil {
+		t.Fatal("RegisterFileGroup failed:", err)
+	}
+
+	if _, err := RegisterFileGroup("file", path, true); !errors.Is(err, ErrGroupExists) {
+		t.Error("RegisterFileGroup failed: expected ErrGroupExists, recieved", err)
+	}
+
+	SetRotationSize(group, 64)
+	Infog(group, "Test first line is long enough")
+	Infog(group, "Test second line rotates")
+
+	UnregisterGroup(group)
+	Done()
+
+	rotated, err := os.ReadFile(path + ".1")
+	if err != nil {
+		t.Fatal("RegisterFileGroup failed: no rotated file:", err)
+	}
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("RegisterFileGroup failed:", err)
+	}
+
+	if match, _ := regexp.Match(`Test first line`, rotated); !match {
+		t.Error("RegisterFileGroup failed: rotated file contains:\n", string(rotated))
+	}
+	if match, _ := regexp.Match(`^`+timeFormat+` INFO \[file\] Test second line rotates\n$`, current); !match {
+		t.Error("RegisterFileGroup failed: current file contains:\n", string(current))
+	}
+}
+
+func Test_RegisterDailyFileGroup(t *testing.T) {
+	std.Reset(true)
+
+	dir := t.TempDir()
+	SetClock(func() time.Time {
+		return time.Date(2024, 6, 1, 23, 59, 0, 0, time.UTC)
+	})
+
+	group, err := RegisterDailyFileGroup("daily", filepath.Join(dir, "audit.log"), true)
+	if err != nil {
+		t.Fatal("RegisterDailyFileGroup failed:", err)
+	}
+
+	if _, err := RegisterDailyFileGroup("daily", filepath.Join(dir, "audit.log"), true); !errors.Is(err, ErrGroupExists) {
+		t.Error("RegisterDailyFileGroup failed: expected ErrGroupExists, recieved", err)
+	}
+
+	Infog(group, "Test first day")
+	Flush()
+	SetClock(func() time.Time {
+		return time.Date(2024, 6, 2, 0, 1, 0, 0, time.UTC)
+	})
+	Infog(group, "Test second day")
+
+	UnregisterGroup(group)
+	Done()
+	SetClock(nil)
+
+	first, err := os.ReadFile(filepath.Join(dir, "audit-2024-06-01.log"))
+	if err != nil {
+		t.Fatal("RegisterDailyFileGroup failed:", err)
+	}
+	second, err := os.ReadFile(filepath.Join(dir, "audit-2024-06-02.log"))
+	if err != nil {
+		t.Fatal("RegisterDailyFileGroup failed:", err)
+	}
+
+	if match, _ := regexp.Match(`^2024-6-1 23:59:00\.000000 INFO \[daily\] Test first day\n$`, first); !match {
+		t.Error("RegisterDailyFileGroup failed: first file contains:\n", string(first))
+	}
+	if match, _ := regexp.Match(`^2024-6-2 00:01:00\.000000 INFO \[daily\] Test second day\n$`, second); !match {
+		t.Error("RegisterDailyFileGroup failed: second file contains:\n", string(second))
+	}
+}
+
+func Test_LevelWriter(t *testing.T) {
+	std.Reset(true)
+
+	levelMemFile := &levelLog{}
+	group := RegisterGroup("levelwriter", levelMemFile, true)
+	EnableTrace(true)
+
+	Traceg(group, "Test trace")
+	Infog(group, "Test info")
+	Warng(group, "Test warn")
+	Errorg(group, "Test error")
+	EnableTrace(false)
+
+	Done()
+
+	gold := []Level{LevelTrace, LevelInfo, LevelWarn, LevelError}
+	if len(levelMemFile.levels) != len(gold) {
+		t.Fatal("LevelWriter failed: expected", len(gold), "lines, recieved", len(levelMemFile.levels))
+	}
+
+	for i, lvl := range levelMemFile.levels {
+		if lvl != gold[i] {
+			t.Error("LevelWriter failed: Level mismatch on line", i+1, "Recieved:", lvl)
+		}
+	}
+}
+
+func Test_GroupWriter(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("groupwriter", &logMemFile, true)
+	w := GroupWriter(group, LevelWarn)
+
+	fmt.Fprint(w, "Test line\n")
+	fmt.Fprint(w, "Test first\nTest par")
+	fmt.Fprint(w, "tial\n")
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` WARN \[groupwriter\] Test line\n$`,
+		timeFormat + ` WARN \[groupwriter\] Test first\n$`,
+		timeFormat + ` WARN \[groupwriter\] Test partial\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("GroupWriter failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("GroupWriter failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_RegisterGzipFileGroup(t *testing.T) {
+	std.Reset(true)
+
+	path := filepath.Join(t.TempDir(), "audit.log.gz")
+	group, err := RegisterGzipFileGroup("gzipfile", path, true)
+	if err != nil {
+		t.Fatal("RegisterGzipFileGroup failed:", err)
+	}
+
+	Infog(group, "Test first member")
+	Done()
+
+	std.Reset(false)
+	Infog(group, "Test second member")
+	UnregisterGroup(group)
+	Done()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal("RegisterGzipFileGroup failed:", err)
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal("RegisterGzipFileGroup failed:", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal("RegisterGzipFileGroup failed: invalid archive:", err)
+	}
+
+	gold := `^` + timeFormat + ` INFO \[gzipfile\] Test first member\n` + timeFormat + ` INFO \[gzipfile\] Test second member\n$`
+	if match, _ := regexp.Match(gold, data); !match {
+		t.Error("RegisterGzipFileGroup failed: file contains:\n", string(data))
+	}
+}
+
+func Test_GroupWriterOf(t *testing.T) {
+	std.Reset(true)
+	defer Done()
+
+	var first, second memoryLog
+	group := RegisterGroup("writerof", &first, true)
+
+	if w := GroupWriterOf(group); w != &first {
+		t.Error("GroupWriterOf failed: expected the registered output, recieved", w)
+	}
+
+	SetBatch(group, true)
+	SetGroupOutput(group, &second)
+	Flush()
+	if w := GroupWriterOf(group); w != &second {
+		t.Error("GroupWriterOf failed: expected the new output, recieved", w)
+	}
+
+	if w := GroupWriterOf(group + 1000); w != nil {
+		t.Error("GroupWriterOf failed: expected nil for an unknown group, recieved", w)
+	}
+}
+
+func Test_AddGroupOutput(t *testing.T) {
+	std.Reset(true)
+
+	var first, second memoryLog
+	group := RegisterGroup("fanout", &first, true)
+
+	AddGroupOutput(group, failingLog{})
+	AddGroupOutput(group, &second)
+	Infog(group, "Test fan out")
+
+	Done()
+
+	if len(first) != 1 || len(second) != 1 || first[0] != second[0] {
+		t.Error("AddGroupOutput failed: recieved", first, "and", second)
+	}
+}
+
+func Test_SetErrorHandler(t *testing.T) {
+	std.Reset(true)
+
+	group := RegisterGroup("errorhandler", failingLog{}, true)
+
+	var failedGroups []int
+	SetErrorHandler(func(group int, err error) {
+		failedGroups = append(failedGroups, group)
+	})
+	Infog(group, "Test write fails")
+	SetErrorHandler(nil)
+
+	Done()
+
+	if len(failedGroups) != 1 || failedGroups[0] != group {
+		t.Error("SetErrorHandler failed: recieved", failedGroups)
+	}
+}
+
+func Test_Scoped(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	auditLog := Scoped(RegisterGroup("scoped", &logMemFile, true))
+	EnableTrace(true)
+	EnableCaller(true)
+
+	auditLog.Infof("Test login %s", "bob")
+	auditLog.Trace("Test trace")
+	auditLog.Warn("Test warn")
+	auditLog.Errorf("Test error %d", 1)
+
+	EnableCaller(false)
+	EnableTrace(false)
+	Done()
+
+	gold := []string{
+		timeFormat + ` INFO \[scoped\] trace_test.go:\d+ Test login bob`,
+		timeFormat + ` TRACE \[scoped\] trace_test.go:\d+ Test trace`,
+		timeFormat + ` WARN \[scoped\] trace_test.go:\d+ Test warn`,
+		timeFormat + ` ERROR \[scoped\] trace_test.go:\d+ Test error 1`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("Scoped failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("Scoped failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_TraceFunc(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("tracefunc", &logMemFile, true)
+	EnableCaller(true)
+
+	calls := 0
+	expensive := func() string {
+		calls++
+		return "Test expensive"
+	}
+
+	TraceFunc(group, expensive)
+	EnableTrace(true)
+	Flush()
+	TraceFunc(group, expensive)
+	EnableGroup(group, false)
+	Flush()
+	TraceFunc(group, expensive)
+	EnableTrace(false)
+	EnableCaller(false)
+
+	Done()
+
+	if calls != 1 {
+		t.Error("TraceFunc failed: expected 1 call, recieved", calls)
+	}
+	if len(logMemFile) != 1 {
+		t.Fatal("TraceFunc failed: expected 1 line, recieved", len(logMemFile))
+	}
+	if match, err := regexp.MatchString(timeFormat+` TRACE \[tracefunc\] trace_test.go:\d+ Test expensive`, logMemFile[0]); err != nil || !match {
+		t.Error("TraceFunc failed: Line mismatch. Recieved:\n", logMemFile[0])
+	}
+}
+
+func Test_LogCtx(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("ctx", &logMemFile, true)
+
+	type key struct{}
+	SetContextIDKey(key{})
+	defer SetContextIDKey(nil)
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "abc123"))
+	InfogfCtx(ctx, group, "Test request %d", 1)
+	cancel()
+	InfogCtx(ctx, group, "Test cancelled")
+	InfogCtx(context.Background(), group, "Test no id")
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` INFO \[ctx\] \[id=abc123\] Test request 1`,
+		timeFormat + ` INFO \[ctx\] Test no id`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("LogCtx failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("LogCtx failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_LogID(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("id", &logMemFile, true)
+
+	InfogfID("abc123", group, "Test request %d", 1)
+	WarngID("", group, "Test no id")
+
+	Done()
+
+	gold := []string{
+		timeFormat + ` INFO \[id\] \[id=abc123\] Test request 1`,
+		timeFormat + ` WARN \[id\] Test no id`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("LogID failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("LogID failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetSynchronous(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("synchronous", &logMemFile, true)
+
+	SetSynchronous(true)
+	Infog(group, "Test synchronous")
+	if len(logMemFile) != 1 {
+		t.Error("SetSynchronous failed: expected 1 line, recieved", len(logMemFile))
+	}
+
+	EnableGroup(group, false)
+	Infog(group, "Test disabled")
+	if len(logMemFile) != 1 {
+		t.Error("SetSynchronous failed: expected 1 line, recieved", len(logMemFile))
+	}
+	SetSynchronous(false)
+
+	Done()
+}
+
+func Test_SetEnabled(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("enabled", &logMemFile, true)
+
+	SetEnabled(false)
+	Errorg(group, "Test disabled")
+	SetEnabled(true)
+	Infog(group, "Test enabled")
+
+	Done()
+
+	if len(logMemFile) != 1 {
+		t.Fatal("SetEnabled failed: expected 1 line, recieved", len(logMemFile))
+	}
+	if match, err := regexp.MatchString(timeFormat+` INFO \[enabled\] Test enabled`, logMemFile[0]); err != nil || !match {
+		t.Error("SetEnabled failed: Recieved:\n", logMemFile[0])
+	}
+}
+
+// implements fmt.Stringer, counting how often it is formatted
+type countingStringer struct {
+	count *int
+}
+
+func (c countingStringer) String() string {
+	*c.count++
+	return "counted"
+}
+
+func Test_DeferredFormat(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("deferred", &logMemFile, true)
+	EnableTrace(false)
+
+	count := 0
+	Traceg(group, countingStringer{&count})
+	Infogf(group, "Test %v", countingStringer{&count})
+
+	Done()
+
+	if count != 1 {
+		t.Error("DeferredFormat failed: expected 1 formatting, recieved", count)
+	}
+	if len(logMemFile) != 1 {
+		t.Error("DeferredFormat failed: expected 1 line, recieved", len(logMemFile))
+	}
+}
+
+func Test_EnableGroupByPrefix(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	db := RegisterGroup("db", &logMemFile, true)
+	query := RegisterGroup("db.query", &logMemFile, true)
+	web := RegisterGroup("web", &logMemFile, true)
+
+	EnableGroupByPrefix("db", false)
+	Infog(db, "Test db off")
+	Infog(query, "Test query off")
+	Infog(web, "Test web on")
+
+	EnableGroupByPrefix("db.", true)
+	Infog(db, "Test db still off")
+	Infog(query, "Test query on")
+
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` INFO \[web\] Test web on`,
+		`^` + timeFormat + ` INFO \[db.query\] Test query on`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("EnableGroupByPrefix failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("EnableGroupByPrefix failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_EnableRingBuffer(t *testing.T) {
+	std.Reset(true)
+
+	group := RegisterDiscardGroup("ring", true)
+
+	if lines := RingBuffer(group); lines != nil {
+		t.Error("EnableRingBuffer failed: expected no buffer, recieved", lines)
+	}
+
+	EnableRingBuffer(group, 2)
+	Infog(group, "Test first")
+	Infog(group, "Test second")
+	Infog(group, "Test third")
+	Flush()
+
+	lines := RingBuffer(group)
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` INFO \[ring\] Test second$`,
+		`^` + timeFormat + ` INFO \[ring\] Test third$`,
+	}
+
+	if len(lines) != len(gold) {
+		t.Fatal("EnableRingBuffer failed: expected", len(gold), "lines, recieved", lines)
+	}
+
+	for i, line := range lines {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("EnableRingBuffer failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetGlobalFields(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 3)
+
+	group := RegisterGroup("globalfields", &logMemFile, true)
+
+	global := map[string]interface{}{"service": "api", "version": 2}
+	SetGlobalFields(global)
+	global["service"] = "changed"
+
+	Infog(group, "Test text")
+	WithFields(Fields{"version": 3}).Infog(group, "Test override")
+	SetFormat(FormatJSON)
+	Infog(group, "Test json")
+	SetFormat(FormatText)
+	SetGlobalFields(nil)
+	Infog(group, "Test removed")
+
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` INFO \[globalfields\] Test text service=api version=2\n$`,
+		`^` + timeFormat + ` INFO \[globalfields\] Test override service=api version=3\n$`,
+		`^\{"time":"[^"]+","level":"info","group":"globalfields","msg":"Test json","service":"api","version":2\}\n$`,
+		`^` + timeFormat + ` INFO \[globalfields\] Test removed\n$`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("SetGlobalFields failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("SetGlobalFields failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_ForceInfo(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 2)
+
+	group := RegisterGroup("force", &logMemFile, false)
+
+	SetMinLevel(LevelError)
+	Infog(group, "Test filtered")
+	ForceInfo(group, "Test forced")
+	ForceInfof(group, "Test %s", "forced format")
+	SetEnabled(false)
+	ForceInfo(group, "Test disabled")
+	SetEnabled(true)
+	SetMinLevel(LevelInfo)
+
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` INFO \[force\] Test forced`,
+		`^` + timeFormat + ` INFO \[force\] Test forced format`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("ForceInfo failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("ForceInfo failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_SetGroupCreatedHook(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	var created []GroupInfo
+
+	SetGroupCreatedHook(func(info GroupInfo) {
+		if IsGroupEnabled(info.ID) != info.Enabled {
+			t.Error("SetGroupCreatedHook failed: IsGroupEnabled does not match", info)
+		}
+		created = append(created, info)
+	})
+	group := RegisterGroup("grouphook", &logMemFile, false)
+	RegisterGroupE("grouphook", &logMemFile, true)
+	SetGroupCreatedHook(nil)
+	RegisterGroup("grouphook2", &logMemFile, true)
+
+	Done()
+
+	if len(created) != 1 {
+		t.Fatal("SetGroupCreatedHook failed: expected 1 group, recieved", created)
+	}
+	if gold := (GroupInfo{ID: group, Name: "grouphook", Enabled: false}); created[0] != gold {
+		t.Error("SetGroupCreatedHook failed: expected", gold, "recieved", created[0])
+	}
+}
+
+func Test_WithTrace(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("withtrace", &logMemFile, true)
+
+	SetMinLevel(LevelWarn)
+	Traceg(group, "Test before")
+	WithTrace(func() {
+		Traceg(group, "Test inside")
+	})
+	Traceg(group, "Test after")
+	Infog(group, "Test after info")
+
+	func() {
+		defer func() { recover() }()
+		WithTrace(func() {
+			panic("Test panic")
+		})
+	}()
+	Flush()
+	if IsTraceEnabled() {
+		t.Error("WithTrace failed: trace left enabled after panic")
+	}
+
+	SetMinLevel(LevelInfo)
+	Done()
+
+	gold := []string{
+		`^` + timeFormat + ` TRACE \[withtrace\] Test inside`,
+	}
+
+	if len(logMemFile) != len(gold) {
+		t.Fatal("WithTrace failed: expected", len(gold), "lines, recieved", len(logMemFile))
+	}
+
+	for i, line := range logMemFile {
+		if match, err := regexp.MatchString(gold[i], line); err != nil || !match {
+			t.Error("WithTrace failed: Line mismatch on line", i+1, "Recieved:\n", line)
+		}
+	}
+}
+
+func Test_StartTimer(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 1)
+
+	group := RegisterGroup("timer", &logMemFile, true)
+
+	timer := StartTimer(group, "Test timer")
+	time.Sleep(2 * time.Millisecond)
+	elapsed := timer.Stop()
+
+	Done()
+
+	if elapsed < 2*time.Millisecond {
+		t.Error("StartTimer failed: expected at least 2ms, recieved", elapsed)
+	}
+
+	gold := `^` + timeFormat + ` INFO \[timer\] Test timer took \d+(\.\d)?ms\n$`
+	if len(logMemFile) != 1 {
+		t.Fatal("StartTimer failed: expected 1 line, recieved", len(logMemFile))
+	}
+	if match, err := regexp.MatchString(gold, logMemFile[0]); err != nil || !match {
+		t.Error("StartTimer failed: Line mismatch Recieved:\n", logMemFile[0])
+	}
+}
+
+func Test_roundDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		1234567 * time.Nanosecond:             "1.2ms",
+		1250 * time.Nanosecond:                "1.3µs",
+		999 * time.Nanosecond:                 "999ns",
+		2345 * time.Millisecond:               "2.3s",
+		90*time.Second + 400*time.Millisecond: "1m30s",
+	}
+	for d, gold := range tests {
+		if s := roundDuration(d).String(); s != gold {
+			t.Error("roundDuration failed: expected", gold, "recieved", s)
+		}
+	}
+}
+
+func Test_RegisterDiscardGroup(t *testing.T) {
+	std.Reset(true)
+
+	count := 0
+	group := RegisterDiscardGroup("discard", true)
+	Infogf(group, "Test %v", countingStringer{&count})
+
+	SetBatch(group, true)
+	Infogf(group, "Test batched %v", countingStringer{&count})
+
+	Done()
+
+	if count != 0 {
+		t.Error("RegisterDiscardGroup failed: expected no formatting, recieved", count)
+	}
+}
+
+func Test_Fatal(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("fatal", &logMemFile, true)
+
+	exitCode := -1
+	osExit = func(code int) {
+		exitCode = code
+	}
+	defer func() {
+		osExit = os.Exit
+	}()
+
+	Fatalgf(group, "Test fatal %d", 1)
+
+	if exitCode != FatalExitCode {
+		t.Error("Fatal failed: expected exit code", FatalExitCode, "recieved", exitCode)
+	}
+	if len(logMemFile) != 1 {
+		t.Fatal("Fatal failed: expected 1 line before exit, recieved", len(logMemFile))
+	}
+	if match, err := regexp.MatchString(timeFormat+` ERROR \[fatal\] Test fatal 1`, logMemFile[0]); err != nil || !match {
+		t.Error("Fatal failed: Recieved:\n", logMemFile[0])
+	}
+
+	Done()
+}
+
+func Test_Panic(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	group := RegisterGroup("panic", &logMemFile, true)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "Test panic 1" {
+				t.Error("Panic failed: recovered", r)
+			}
+		}()
+		Panicgf(group, "Test panic %d", 1)
+	}()
+
+	if len(logMemFile) != 1 {
+		t.Fatal("Panic failed: expected 1 line before panic, recieved", len(logMemFile))
+	}
+	if match, err := regexp.MatchString(timeFormat+` ERROR \[panic\] Test panic 1`, logMemFile[0]); err != nil || !match {
+		t.Error("Panic failed: Recieved:\n", logMemFile[0])
+	}
+
+	Done()
+}
+
+func Test_ListGroups(t *testing.T) {
+	std.Reset(true)
+
+	group := RegisterGroup("list", &memoryLog{}, false)
+	Flush()
+
+	list := ListGroups()
+	if len(list) == 0 || list[0] != (GroupInfo{ID: DefaultGroupId, Name: "", Enabled: true}) {
+		t.Error("ListGroups failed: missing default group in", list)
+	}
+
+	found := false
+	for _, info := range list {
+		if info == (GroupInfo{ID: group, Name: "list", Enabled: false}) {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("ListGroups failed: missing registered group in", list)
+	}
+
+	Done()
+}
+
+func Test_IsEnabled(t *testing.T) {
+	std.Reset(true)
+
+	group := RegisterGroup("isenabled", &memoryLog{}, true)
+
+	EnableTrace(true)
+	EnableGroup(group, false)
+	Flush()
+
+	if !IsTraceEnabled() {
+		t.Error("IsTraceEnabled failed: expected true")
+	}
+	if IsGroupEnabled(group) {
+		t.Error("IsGroupEnabled failed: expected false")
+	}
+	if !IsGroupEnabled(DefaultGroupId) {
+		t.Error("IsGroupEnabled failed: expected default group enabled")
+	}
+	if IsGroupEnabled(1000) {
+		t.Error("IsGroupEnabled failed: expected unknown group disabled")
+	}
+
+	EnableTrace(false)
+	Flush()
+
+	if IsTraceEnabled() {
+		t.Error("IsTraceEnabled failed: expected false")
+	}
+
+	Done()
+}
+
+func Test_Concurrency(t *testing.T) {
+	std.Reset(true)
+
+	var logMemFile memoryLog
+	logMemFile = make([]string, 0, 4)
+
+	SetDefaultGroup(&logMemFile)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			group := RegisterGroup(fmt.Sprint("concurrency", i), &memoryLog{}, true)
+			for j := 0; j < 100; j++ {
+				Infog(group, "Test group", j)
+				Info("Test default", j)
+				EnableGroup(group, j%2 == 0)
+				ListGroups()
+				IsGroupEnabled(group)
+			}
+			SetDefaultGroup(&logMemFile)
+		}(i)
+	}
+	wg.Wait()
+
+	Done()
+}
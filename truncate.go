@@ -0,0 +1,46 @@
+package trace
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+type cmdSetMaxMessageLength struct {
+	n int
+}
+
+func (c *cmdSetMaxMessageLength) do(l *Logger) {
+	l.maxMsgLength = c.n
+}
+
+// truncate is a helper function for shortening msg to at most n bytes, cut on
+// a rune boundary, followed by a note of its original length
+func truncate(msg string, n int) string {
+	if len(msg) <= n {
+		return msg
+	}
+
+	cut := n
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return msg[:cut] + "...(truncated, " + strconv.Itoa(len(msg)) + " bytes)"
+}
+
+// SetMaxMessageLength sets the length in bytes above which messages are
+// truncated, as a safety valve against runaway log statements such as a dump
+// of a huge struct. Truncated messages end like "...(truncated, 50321 bytes)"
+// with their original length, and are never cut inside a UTF-8 character.
+// Fields are not truncated. Zero, the default, means no limit.
+func (l *Logger) SetMaxMessageLength(n int) {
+	l.enqueue(&cmdSetMaxMessageLength{n})
+}
+
+// SetMaxMessageLength sets the length in bytes above which messages are
+// truncated, as a safety valve against runaway log statements such as a dump
+// of a huge struct. Truncated messages end like "...(truncated, 50321 bytes)"
+// with their original length, and are never cut inside a UTF-8 character.
+// Fields are not truncated. Zero, the default, means no limit.
+func SetMaxMessageLength(n int) {
+	std.SetMaxMessageLength(n)
+}
@@ -0,0 +1,76 @@
+package trace
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// WriterFunc adapts a function to an io.Writer, so that log lines can be sent
+// to a channel or a webhook without declaring a type:
+//
+//	trace.RegisterGroup("hook", trace.WriterFunc(send), true)
+//
+// Used as a group output, the function runs on the log goroutine with each
+// formatted line, so it must not block for long or log. The line is only
+// valid during the call and must be copied to be kept.
+type WriterFunc func(p []byte) (n int, err error)
+
+// Write calls f(p)
+func (f WriterFunc) Write(p []byte) (n int, err error) {
+	return f(p)
+}
+
+// groupWriter is an io.Writer logging each line written to it
+type groupWriter struct {
+	logger *Logger
+	group  int
+	lvl    Level
+
+	mu sync.Mutex
+
+	// Data after the last newline, logged once the line is complete
+	partial []byte
+}
+
+func (w *groupWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data := append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		w.logger.log(w.group, w.lvl, "", string(bytes.TrimSuffix(data[:i], []byte("\r"))))
+		data = data[i+1:]
+	}
+	w.partial = append([]byte(nil), data...)
+
+	return len(p), nil
+}
+
+// GroupWriter returns an io.Writer that logs every line written to it to the
+// group at the given level, without the trailing newline, so that output of
+// other packages can be captured:
+//
+//	log.SetOutput(trace.GroupWriter(group, trace.LevelInfo))
+//
+// A buffer holding several lines is logged as several messages. Data after the
+// last newline is kept until the line is completed by a later write.
+func (l *Logger) GroupWriter(group int, level Level) io.Writer {
+	return &groupWriter{logger: l, group: group, lvl: level}
+}
+
+// GroupWriter returns an io.Writer that logs every line written to it to the
+// group at the given level, without the trailing newline, so that output of
+// other packages can be captured:
+//
+//	log.SetOutput(trace.GroupWriter(group, trace.LevelInfo))
+//
+// A buffer holding several lines is logged as several messages. Data after the
+// last newline is kept until the line is completed by a later write.
+func GroupWriter(group int, level Level) io.Writer {
+	return std.GroupWriter(group, level)
+}